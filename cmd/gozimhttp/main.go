@@ -3,13 +3,16 @@ package main
 import (
 	"embed"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"html/template"
+	"io"
 	"log"
-	"math/rand"
 	"net/http"
+	"net/url"
 	"strings"
+	"time"
 
 	"github.com/akhenakh/zim-cgo/zim"
 )
@@ -22,7 +25,7 @@ type Server struct {
 	searcher     *zim.Searcher
 	suggSearcher *zim.SuggestionSearcher
 	templates    *template.Template
-	entryCount   uint64
+	title        string
 }
 
 func main() {
@@ -40,10 +43,14 @@ func main() {
 	defer archive.Close()
 
 	searcher, err := zim.NewSearcher(archive)
-	if err != nil {
+	switch {
+	case errors.Is(err, zim.ErrNoFulltextIndex):
+		log.Println("archive has no fulltext index, full text search disabled")
+	case err != nil:
 		log.Fatalf("failed to create searcher: %v", err)
+	default:
+		defer searcher.Close()
 	}
-	defer searcher.Close()
 
 	suggSearcher, err := zim.NewSuggestionSearcher(archive)
 	if err != nil {
@@ -56,20 +63,29 @@ func main() {
 		log.Fatalf("failed to parse templates: %v", err)
 	}
 
+	title := "ZIM Reader"
+	if md, err := archive.Metadata(); err != nil {
+		log.Printf("failed to read archive metadata: %v", err)
+	} else if md.Title != "" {
+		title = md.Title
+	}
+
 	s := &Server{
 		archive:      archive,
 		searcher:     searcher,
 		suggSearcher: suggSearcher,
 		templates:    tmpl,
-		entryCount:   archive.GetEntryCount(),
+		title:        title,
 	}
 
 	http.HandleFunc("/content/", s.handleContent)
+	http.HandleFunc("/title/", s.handleTitle)
 	http.HandleFunc("/search/suggestions", s.handleSuggestions)
 	http.HandleFunc("/search/results", s.handleSearchResults)
 	http.HandleFunc("/api/random", s.handleRandomAPI)
 	http.HandleFunc("/api/main", s.handleMainEntry)
 	http.HandleFunc("/random", s.handleRandom)
+	http.HandleFunc("/favicon.ico", s.handleFavicon)
 	http.HandleFunc("/", s.handleMain)
 
 	log.Println("server starting on :8080")
@@ -86,30 +102,52 @@ func (s *Server) handleMain(w http.ResponseWriter, r *http.Request) {
 	s.renderShell(w, r.URL.Path)
 }
 
-func (s *Server) handleRandom(w http.ResponseWriter, r *http.Request) {
-	idx := rand.Uint32() % uint32(s.entryCount)
+func isHTML(mimetype string) bool {
+	return strings.HasPrefix(mimetype, "text/html")
+}
 
-	entry, err := s.archive.GetEntryByIndex(idx)
+// randomHTMLPath picks a random HTML article and returns its resolved path
+func (s *Server) randomHTMLPath() (string, error) {
+	entry, err := s.archive.GetRandomEntryFiltered(isHTML)
 	if err != nil {
-		http.Error(w, "failed to get random entry", http.StatusInternalServerError)
-		return
+		return "", err
 	}
 	defer entry.Close()
 
 	item, err := entry.GetItem(true)
 	if err != nil {
-		http.Error(w, "failed to get random item", http.StatusInternalServerError)
-		return
+		return "", err
 	}
 	defer item.Close()
 
-	path := item.GetPath()
-	if path == "" {
-		http.Error(w, "random entry has no path", http.StatusInternalServerError)
+	return item.GetPath(), nil
+}
+
+func (s *Server) handleRandom(w http.ResponseWriter, r *http.Request) {
+	path, err := s.randomHTMLPath()
+	if err != nil || path == "" {
+		http.Error(w, "no HTML page found", http.StatusNotFound)
 		return
 	}
 
-	http.Redirect(w, r, "/content/"+path, http.StatusFound)
+	target := url.URL{Path: "/content/" + path}
+	http.Redirect(w, r, target.EscapedPath(), http.StatusFound)
+}
+
+func (s *Server) handleFavicon(w http.ResponseWriter, r *http.Request) {
+	ill, err := s.archive.GetIllustration(48)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if ill.Mimetype != "" {
+		w.Header().Set("Content-Type", ill.Mimetype)
+	}
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(ill.Data)))
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	w.WriteHeader(http.StatusOK)
+	w.Write(ill.Data)
 }
 
 func (s *Server) handleMainEntry(w http.ResponseWriter, r *http.Request) {
@@ -138,29 +176,8 @@ func (s *Server) handleMainEntry(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) handleRandomAPI(w http.ResponseWriter, r *http.Request) {
-	var path string
-	for range 100 {
-		idx := rand.Uint32() % uint32(s.entryCount)
-		entry, err := s.archive.GetEntryByIndex(idx)
-		if err != nil {
-			continue
-		}
-		defer entry.Close()
-
-		item, err := entry.GetItem(true)
-		if err != nil {
-			continue
-		}
-		defer item.Close()
-
-		mimetype := item.GetMimetype()
-		if strings.HasPrefix(mimetype, "text/html") {
-			path = item.GetPath()
-			break
-		}
-	}
-
-	if path == "" {
+	path, err := s.randomHTMLPath()
+	if err != nil || path == "" {
 		http.Error(w, "no HTML page found", http.StatusNotFound)
 		return
 	}
@@ -206,6 +223,11 @@ func (s *Server) handleSearchResults(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if s.searcher == nil {
+		http.Error(w, "full text search is not available for this archive", http.StatusNotImplemented)
+		return
+	}
+
 	queryObj, err := zim.NewQuery(query)
 	if err != nil {
 		http.Error(w, "failed to create query", http.StatusInternalServerError)
@@ -232,13 +254,21 @@ func (s *Server) handleSearchResults(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleContent(w http.ResponseWriter, r *http.Request) {
 	path := strings.TrimPrefix(r.URL.Path, "/content/")
 
-	entry, err := s.archive.GetEntryByPath(path)
+	entry, err := s.archive.ResolvePathWithOptions(path, zim.ResolveOptions{CaseInsensitive: true, TitleFallback: true})
 	if err != nil {
 		http.NotFound(w, r)
 		return
 	}
 	defer entry.Close()
 
+	// The base tag is built from the request path, so fallback matches must
+	// land on the entry path first
+	if entry.Path() != path {
+		target := url.URL{Path: "/content/" + entry.Path()}
+		http.Redirect(w, r, target.EscapedPath(), http.StatusFound)
+		return
+	}
+
 	item, err := entry.GetItem(true)
 	if err != nil {
 		http.Error(w, "failed to get item", http.StatusInternalServerError)
@@ -246,27 +276,54 @@ func (s *Server) handleContent(w http.ResponseWriter, r *http.Request) {
 	}
 	defer item.Close()
 
-	data := item.GetData()
 	mimetype := item.GetMimetype()
-
-	if strings.HasPrefix(mimetype, "text/html") {
-		baseURL := getBaseURL(path)
-		baseTag := "<base href=\"" + baseURL + "\">"
-		data = []byte(baseTag + string(data))
-	}
-
 	if mimetype != "" {
 		w.Header().Set("Content-Type", mimetype)
 	}
-	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(data)))
+
+	if !isHTML(mimetype) {
+		http.ServeContent(w, r, path, time.Time{}, item.Reader())
+		return
+	}
+
+	baseURL := getBaseURL(path)
+	baseTag := "<base href=\"" + baseURL + "\">"
+
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", uint64(len(baseTag))+item.GetSize()))
 	w.WriteHeader(http.StatusOK)
-	w.Write(data)
+	io.WriteString(w, baseTag)
+	if _, err := item.WriteTo(w); err != nil {
+		log.Printf("failed to write content of %s: %v", path, err)
+	}
+}
+
+func (s *Server) handleTitle(w http.ResponseWriter, r *http.Request) {
+	title := strings.TrimPrefix(r.URL.Path, "/title/")
+
+	entry, err := s.archive.GetEntryByTitle(title)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer entry.Close()
+
+	item, err := entry.GetItem(true)
+	if err != nil {
+		http.Error(w, "failed to get item", http.StatusInternalServerError)
+		return
+	}
+	defer item.Close()
+
+	target := url.URL{Path: "/content/" + item.GetPath()}
+	http.Redirect(w, r, target.EscapedPath(), http.StatusFound)
 }
 
 func (s *Server) renderShell(w http.ResponseWriter, iframeSrc string) {
 	data := struct {
+		Title     string
 		IframeSrc string
 	}{
+		Title:     s.title,
 		IframeSrc: iframeSrc,
 	}
 
@@ -281,6 +338,20 @@ func getBaseURL(path string) string {
 	return "/content/" + dir
 }
 
+// formatSize renders a byte count for humans, e.g. "12.3 KB"
+func formatSize(size uint64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := uint64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
 func (s *Server) renderSearchResults(w http.ResponseWriter, query string, results []zim.SearchResult) {
 	type searchResult struct {
 		Path      string
@@ -288,6 +359,8 @@ func (s *Server) renderSearchResults(w http.ResponseWriter, query string, result
 		Snippet   template.HTML
 		Score     int
 		WordCount int
+		Mimetype  string
+		Size      string
 	}
 	sr := make([]searchResult, len(results))
 	for i, r := range results {
@@ -301,6 +374,8 @@ func (s *Server) renderSearchResults(w http.ResponseWriter, query string, result
 			Snippet:   template.HTML(snippet),
 			Score:     r.Score,
 			WordCount: r.WordCount,
+			Mimetype:  r.Mimetype,
+			Size:      formatSize(r.Size),
 		}
 	}
 
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/akhenakh/zim-cgo/zim"
+)
+
+func main() {
+	zimPath := flag.String("z", "", "path to zim file")
+	asJSON := flag.Bool("json", false, "print statistics as JSON")
+	flag.Parse()
+
+	if *zimPath == "" {
+		log.Fatal("please provide a zim file path using -z flag")
+	}
+
+	archive, err := zim.NewArchive(*zimPath)
+	if err != nil {
+		log.Fatalf("failed to open zim archive: %v", err)
+	}
+	defer archive.Close()
+
+	stats, err := archive.Stats()
+	if err != nil {
+		log.Fatalf("failed to compute statistics: %v", err)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(stats); err != nil {
+			log.Fatalf("failed to encode statistics: %v", err)
+		}
+		return
+	}
+
+	printTable(stats)
+}
+
+func printTable(stats *zim.Stats) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "All entries\t%d\n", stats.AllEntryCount)
+	fmt.Fprintf(w, "User entries\t%d\n", stats.EntryCount)
+	fmt.Fprintf(w, "Articles\t%d\n", stats.ArticleCount)
+	fmt.Fprintf(w, "Media\t%d\n", stats.MediaCount)
+	fmt.Fprintf(w, "Redirects\t%d\n", stats.RedirectCount)
+	fmt.Fprintf(w, "Total size\t%d\n", stats.TotalSize)
+	fmt.Fprintln(w)
+
+	mimetypes := make([]string, 0, len(stats.Mimetypes))
+	for mimetype := range stats.Mimetypes {
+		mimetypes = append(mimetypes, mimetype)
+	}
+	for mimetype := range stats.Counter {
+		if _, ok := stats.Mimetypes[mimetype]; !ok {
+			mimetypes = append(mimetypes, mimetype)
+		}
+	}
+	sort.Strings(mimetypes)
+
+	fmt.Fprintln(w, "MIMETYPE\tITEMS\tCOUNTER\tSIZE")
+	for _, mimetype := range mimetypes {
+		ms := stats.Mimetypes[mimetype]
+		counter := "-"
+		if c, ok := stats.Counter[mimetype]; ok {
+			counter = fmt.Sprintf("%d", c)
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%d\n", mimetype, ms.Items, counter, ms.Size)
+	}
+	w.Flush()
+}
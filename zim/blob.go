@@ -0,0 +1,65 @@
+package zim
+
+/*
+#include "zim_wrapper.h"
+*/
+import "C"
+import "unsafe"
+
+// Blob gives direct access to the content of an Item, without copying it
+// out of libzim. The memory stays valid until Close is called or the Blob is
+// garbage collected, so the Blob must be kept reachable while its bytes are
+// in use.
+type Blob struct {
+	h    *handle
+	ptr  C.zim_blob_t
+	data []byte
+}
+
+// GetBlob returns the content of the item as a Blob
+func (i *Item) GetBlob() (*Blob, error) {
+	if !i.h.acquire() {
+		return nil, closedError("GetBlob")
+	}
+	defer i.h.release()
+
+	var cerr C.zim_error_t
+	ptr := C.zim_item_get_blob(i.ptr, &cerr)
+	if ptr == nil {
+		return nil, newError("GetBlob", &cerr, nil)
+	}
+
+	b := &Blob{ptr: ptr}
+	if size := int(C.zim_blob_size(ptr)); size > 0 {
+		b.data = unsafe.Slice((*byte)(unsafe.Pointer(C.zim_blob_data(ptr))), size)
+	}
+	b.h = newHandle("Blob", func() { C.zim_blob_free(ptr) })
+	track(b, b.h)
+	return b, nil
+}
+
+// Bytes returns the content as a slice over libzim memory. The slice must not
+// be modified nor used after the Blob is closed.
+func (b *Blob) Bytes() []byte {
+	if !b.h.acquire() {
+		return nil
+	}
+	defer b.h.release()
+
+	return b.data
+}
+
+// Len returns the size of the content
+func (b *Blob) Len() int {
+	if !b.h.acquire() {
+		return 0
+	}
+	defer b.h.release()
+
+	return len(b.data)
+}
+
+// Close frees the content. It is safe to call several times.
+func (b *Blob) Close() {
+	b.h.close()
+}
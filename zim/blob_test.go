@@ -0,0 +1,88 @@
+package zim
+
+import (
+	"testing"
+)
+
+func TestItem_GetBlob(t *testing.T) {
+	content := "<html><body>blob content</body></html>"
+	path := createTestArchive(t, "index.html",
+		testItem{path: "index.html", mimetype: "text/html", title: "Home", content: content, front: true},
+	)
+
+	archive, err := NewArchive(path)
+	if err != nil {
+		t.Fatalf("Failed to open generated ZIM archive: %v", err)
+	}
+	defer archive.Close()
+
+	entry, err := archive.GetEntryByPath("index.html")
+	if err != nil {
+		t.Fatalf("Failed to get entry: %v", err)
+	}
+	defer entry.Close()
+
+	item, err := entry.GetItem(true)
+	if err != nil {
+		t.Fatalf("Failed to get item: %v", err)
+	}
+	defer item.Close()
+
+	blob, err := item.GetBlob()
+	if err != nil {
+		t.Fatalf("Failed to get blob: %v", err)
+	}
+
+	if blob.Len() != len(content) || string(blob.Bytes()) != content {
+		t.Errorf("Expected blob content %q, got %q", content, blob.Bytes())
+	}
+
+	// GetData must return an independent copy
+	data := item.GetData()
+	if string(data) != content {
+		t.Errorf("Expected GetData content %q, got %q", content, data)
+	}
+
+	blob.Close()
+	if blob.Bytes() != nil {
+		t.Errorf("Expected closed blob to expose no bytes")
+	}
+	blob.Close()
+
+	if string(data) != content {
+		t.Errorf("Expected GetData copy to outlive the blob")
+	}
+}
+
+func BenchmarkItem_GetBlob(b *testing.B) {
+	archive, err := NewArchive(getTestSearchZimPath())
+	if err != nil {
+		b.Fatalf("Failed to open valid ZIM archive: %v", err)
+	}
+	defer archive.Close()
+
+	entry, err := archive.GetMainEntry()
+	if err != nil {
+		b.Fatalf("Failed to get main entry: %v", err)
+	}
+	defer entry.Close()
+
+	item, err := entry.GetItem(true)
+	if err != nil {
+		b.Fatalf("Failed to get item: %v", err)
+	}
+	defer item.Close()
+
+	b.Run("GetData", func(b *testing.B) {
+		for b.Loop() {
+			_ = item.GetData()
+		}
+	})
+	b.Run("GetBlob", func(b *testing.B) {
+		for b.Loop() {
+			blob, _ := item.GetBlob()
+			_ = blob.Bytes()
+			blob.Close()
+		}
+	})
+}
@@ -0,0 +1,95 @@
+package zim
+
+/*
+#include "zim_wrapper.h"
+*/
+import "C"
+
+// SetClusterCacheMaxSize bounds the memory, in bytes, used to keep
+// decompressed clusters. The cluster cache is shared by every archive of the
+// process; shrinking it evicts clusters immediately.
+func SetClusterCacheMaxSize(size uint64) error {
+	var cerr C.zim_error_t
+	if !bool(C.zim_set_cluster_cache_max_size(C.uint64_t(size), &cerr)) {
+		return newError("SetClusterCacheMaxSize", &cerr, nil)
+	}
+	return nil
+}
+
+// ClusterCacheMaxSize returns the maximum size, in bytes, of the process-wide
+// cluster cache
+func ClusterCacheMaxSize() uint64 {
+	return uint64(C.zim_get_cluster_cache_max_size())
+}
+
+// ClusterCacheSize returns the memory, in bytes, currently used by the
+// process-wide cluster cache
+func ClusterCacheSize() uint64 {
+	return uint64(C.zim_get_cluster_cache_current_size())
+}
+
+// SetDirentCacheMaxSize sets the number of directory entries cached by the archive
+func (a *Archive) SetDirentCacheMaxSize(size int) error {
+	if size < 0 {
+		return &Error{Op: "SetDirentCacheMaxSize", Message: "negative cache size"}
+	}
+	if !a.h.acquire() {
+		return closedError("SetDirentCacheMaxSize")
+	}
+	defer a.h.release()
+
+	var cerr C.zim_error_t
+	if !bool(C.zim_archive_set_dirent_cache_max_size(a.ptr, C.uint64_t(size), &cerr)) {
+		return newError("SetDirentCacheMaxSize", &cerr, nil)
+	}
+	return nil
+}
+
+// DirentCacheMaxSize returns the number of directory entries the archive may cache
+func (a *Archive) DirentCacheMaxSize() int {
+	if !a.h.acquire() {
+		return 0
+	}
+	defer a.h.release()
+
+	return int(C.zim_archive_get_dirent_cache_max_size(a.ptr))
+}
+
+// DirentCacheSize returns the number of directory entries currently cached
+func (a *Archive) DirentCacheSize() int {
+	if !a.h.acquire() {
+		return 0
+	}
+	defer a.h.release()
+
+	return int(C.zim_archive_get_dirent_cache_current_size(a.ptr))
+}
+
+// SetDirentLookupCacheMaxSize sets the number of path ranges kept to speed up
+// lookups by path
+func (a *Archive) SetDirentLookupCacheMaxSize(size int) error {
+	if size < 0 {
+		return &Error{Op: "SetDirentLookupCacheMaxSize", Message: "negative cache size"}
+	}
+	if !a.h.acquire() {
+		return closedError("SetDirentLookupCacheMaxSize")
+	}
+	defer a.h.release()
+
+	var cerr C.zim_error_t
+	if !bool(C.zim_archive_set_dirent_lookup_cache_max_size(a.ptr, C.uint64_t(size), &cerr)) {
+		return newError("SetDirentLookupCacheMaxSize", &cerr, nil)
+	}
+	return nil
+}
+
+// DirentLookupCacheMaxSize returns the number of path ranges kept to speed up
+// lookups by path
+func (a *Archive) DirentLookupCacheMaxSize() int {
+	if !a.h.acquire() {
+		return 0
+	}
+	defer a.h.release()
+
+	return int(C.zim_archive_get_dirent_lookup_cache_max_size(a.ptr))
+}
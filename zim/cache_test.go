@@ -0,0 +1,62 @@
+package zim
+
+import (
+	"testing"
+)
+
+func TestClusterCacheMaxSize(t *testing.T) {
+	orig := ClusterCacheMaxSize()
+	defer SetClusterCacheMaxSize(orig)
+
+	if err := SetClusterCacheMaxSize(1 << 20); err != nil {
+		t.Fatalf("Failed to set cluster cache max size: %v", err)
+	}
+	if got := ClusterCacheMaxSize(); got != 1<<20 {
+		t.Errorf("Expected cluster cache max size %d, got %d", 1<<20, got)
+	}
+
+	archive, err := NewArchive(getTestSearchZimPath())
+	if err != nil {
+		t.Fatalf("Failed to open valid ZIM archive: %v", err)
+	}
+	defer archive.Close()
+
+	entry, err := archive.GetMainEntry()
+	if err != nil {
+		t.Fatalf("Failed to get main entry: %v", err)
+	}
+	defer entry.Close()
+
+	item, err := entry.GetItem(true)
+	if err != nil {
+		t.Fatalf("Failed to get item: %v", err)
+	}
+	defer item.Close()
+	item.GetData()
+
+	if got := ClusterCacheSize(); got > 1<<20 {
+		t.Errorf("Expected cluster cache to stay under %d bytes, got %d", 1<<20, got)
+	}
+}
+
+func TestArchive_DirentCacheMaxSize(t *testing.T) {
+	archive, err := NewArchive(getTestZimPath())
+	if err != nil {
+		t.Fatalf("Failed to open valid ZIM archive: %v", err)
+	}
+	defer archive.Close()
+
+	if err := archive.SetDirentCacheMaxSize(-1); err == nil {
+		t.Error("Expected a negative dirent cache size to be rejected")
+	}
+	if err := archive.SetDirentLookupCacheMaxSize(-1); err == nil {
+		t.Error("Expected a negative dirent lookup cache size to be rejected")
+	}
+
+	if err := archive.SetDirentCacheMaxSize(64); err != nil {
+		t.Fatalf("Failed to set dirent cache max size: %v", err)
+	}
+	if got := archive.DirentCacheMaxSize(); got != 64 {
+		t.Errorf("Expected dirent cache max size 64, got %d", got)
+	}
+}
@@ -0,0 +1,172 @@
+package zim
+
+/*
+#include <stdlib.h>
+#include "zim_wrapper.h"
+*/
+import "C"
+import (
+	"fmt"
+	"unsafe"
+)
+
+// IntegrityCheck identifies one of the archive integrity checks
+type IntegrityCheck int
+
+// The first checks mirror zim_integrity_check_t in zim_wrapper.h
+const (
+	CheckChecksum        IntegrityCheck = iota // Archive checksum matches the content
+	CheckDirentPtrs                            // Dirent pointer list is valid
+	CheckDirentOrder                           // Dirents are sorted by path
+	CheckTitleIndex                            // Title index is valid and sorted
+	CheckClusterPtrs                           // Cluster pointer list is valid
+	CheckClustersOffsets                       // Cluster offsets are consistent
+	CheckDirentMimetypes                       // Dirents reference known mimetypes
+	CheckRedirectLoops                         // No redirect chain loops forever
+)
+
+// AllChecks lists every available integrity check
+var AllChecks = []IntegrityCheck{
+	CheckChecksum,
+	CheckDirentPtrs,
+	CheckDirentOrder,
+	CheckTitleIndex,
+	CheckClusterPtrs,
+	CheckClustersOffsets,
+	CheckDirentMimetypes,
+	CheckRedirectLoops,
+}
+
+func (c IntegrityCheck) String() string {
+	switch c {
+	case CheckChecksum:
+		return "checksum"
+	case CheckDirentPtrs:
+		return "dirent pointers"
+	case CheckDirentOrder:
+		return "dirent order"
+	case CheckTitleIndex:
+		return "title index"
+	case CheckClusterPtrs:
+		return "cluster pointers"
+	case CheckClustersOffsets:
+		return "cluster offsets"
+	case CheckDirentMimetypes:
+		return "dirent mimetypes"
+	case CheckRedirectLoops:
+		return "redirect loops"
+	}
+	return fmt.Sprintf("IntegrityCheck(%d)", int(c))
+}
+
+// CheckOptions selects the checks run by Archive.Check
+type CheckOptions struct {
+	// Checks to run, all of them when empty
+	Checks []IntegrityCheck
+}
+
+// CheckResult is the outcome of a single integrity check
+type CheckResult struct {
+	Check  IntegrityCheck
+	Passed bool
+	Err    error // Reason reported by libzim, if any
+}
+
+// CheckReport is the outcome of Archive.Check
+type CheckReport struct {
+	Results []CheckResult
+}
+
+// OK reports whether every check passed
+func (r *CheckReport) OK() bool {
+	return len(r.Failed()) == 0
+}
+
+// Failed returns the checks that did not pass
+func (r *CheckReport) Failed() []IntegrityCheck {
+	var failed []IntegrityCheck
+	for _, res := range r.Results {
+		if !res.Passed {
+			failed = append(failed, res.Check)
+		}
+	}
+	return failed
+}
+
+// HasChecksum returns true if the archive embeds an MD5 checksum
+func (a *Archive) HasChecksum() bool {
+	if !a.h.acquire() {
+		return false
+	}
+	defer a.h.release()
+
+	return bool(C.zim_archive_has_checksum(a.ptr))
+}
+
+// Checksum returns the checksum stored in the archive as a hex string.
+// It returns ErrNotFound if the archive has no checksum.
+func (a *Archive) Checksum() (string, error) {
+	if !a.h.acquire() {
+		return "", closedError("Checksum")
+	}
+	defer a.h.release()
+
+	var cerr C.zim_error_t
+	cStr := C.zim_archive_get_checksum(a.ptr, &cerr)
+	if cStr == nil {
+		return "", newError("Checksum", &cerr, nil)
+	}
+	defer C.free(unsafe.Pointer(cStr))
+	return C.GoString(cStr), nil
+}
+
+// VerifyChecksum computes the checksum of the whole archive and compares it
+// with the stored one. This reads the entire file.
+func (a *Archive) VerifyChecksum() (bool, error) {
+	if !a.h.acquire() {
+		return false, closedError("VerifyChecksum")
+	}
+	defer a.h.release()
+
+	var cerr C.zim_error_t
+	ok := bool(C.zim_archive_verify_checksum(a.ptr, &cerr))
+	if !ok && cerr.code != C.ZIM_OK {
+		return false, newError("VerifyChecksum", &cerr, nil)
+	}
+	return ok, nil
+}
+
+// Check runs the selected integrity checks and reports which of them failed.
+// Some checks read the entire archive and can take a while on large files.
+func (a *Archive) Check(opts CheckOptions) *CheckReport {
+	checks := opts.Checks
+	if len(checks) == 0 {
+		checks = AllChecks
+	}
+
+	report := &CheckReport{Results: make([]CheckResult, 0, len(checks))}
+	if !a.h.acquire() {
+		for _, check := range checks {
+			report.Results = append(report.Results, CheckResult{Check: check, Err: closedError("Check")})
+		}
+		return report
+	}
+	defer a.h.release()
+
+	for _, check := range checks {
+		var cerr C.zim_error_t
+		var passed bool
+		if check == CheckRedirectLoops {
+			passed = bool(C.zim_archive_check_redirect_loops(a.ptr, &cerr))
+		} else {
+			passed = bool(C.zim_archive_check_integrity(a.ptr, C.zim_integrity_check_t(check), &cerr))
+		}
+
+		res := CheckResult{Check: check, Passed: passed}
+		if cerr.code != C.ZIM_OK {
+			res.Err = newError("Check", &cerr, ErrInvalidArchive)
+		}
+		report.Results = append(report.Results, res)
+	}
+	return report
+}
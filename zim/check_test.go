@@ -0,0 +1,79 @@
+package zim
+
+import (
+	"os"
+	"testing"
+)
+
+func TestArchive_Identity(t *testing.T) {
+	archive, err := NewArchive(getTestZimPath())
+	if err != nil {
+		t.Fatalf("Failed to open valid ZIM archive: %v", err)
+	}
+	defer archive.Close()
+
+	uuid := archive.UUID()
+	if len(uuid) != 36 {
+		t.Errorf("Expected a 36 character UUID, got %q", uuid)
+	}
+
+	info, err := os.Stat(getTestZimPath())
+	if err != nil {
+		t.Fatalf("Failed to stat test archive: %v", err)
+	}
+	if archive.FileSize() != uint64(info.Size()) {
+		t.Errorf("Expected FileSize %d, got %d", info.Size(), archive.FileSize())
+	}
+}
+
+func TestArchive_Checksum(t *testing.T) {
+	archive, err := NewArchive(getTestZimPath())
+	if err != nil {
+		t.Fatalf("Failed to open valid ZIM archive: %v", err)
+	}
+	defer archive.Close()
+
+	if !archive.HasChecksum() {
+		t.Skip("Skipping checksum test because the test ZIM has no checksum.")
+	}
+
+	sum, err := archive.Checksum()
+	if err != nil {
+		t.Fatalf("Failed to read checksum: %v", err)
+	}
+	if len(sum) != 32 {
+		t.Errorf("Expected a 32 character MD5 hex checksum, got %q", sum)
+	}
+
+	ok, err := archive.VerifyChecksum()
+	if err != nil {
+		t.Fatalf("Failed to verify checksum: %v", err)
+	}
+	if !ok {
+		t.Errorf("Expected checksum of the test ZIM to be valid")
+	}
+}
+
+func TestArchive_Check(t *testing.T) {
+	archive, err := NewArchive(getTestZimPath())
+	if err != nil {
+		t.Fatalf("Failed to open valid ZIM archive: %v", err)
+	}
+	defer archive.Close()
+
+	report := archive.Check(CheckOptions{})
+	if len(report.Results) != len(AllChecks) {
+		t.Fatalf("Expected %d results, got %d", len(AllChecks), len(report.Results))
+	}
+	for _, res := range report.Results {
+		t.Logf("Check %s: passed=%v err=%v", res.Check, res.Passed, res.Err)
+	}
+	if !report.OK() {
+		t.Errorf("Expected test ZIM to pass all checks, failed: %v", report.Failed())
+	}
+
+	report = archive.Check(CheckOptions{Checks: []IntegrityCheck{CheckDirentOrder}})
+	if len(report.Results) != 1 || report.Results[0].Check != CheckDirentOrder {
+		t.Errorf("Expected a single dirent order result, got %+v", report.Results)
+	}
+}
@@ -0,0 +1,79 @@
+package zim
+
+/*
+#include <stdlib.h>
+#include "zim_wrapper.h"
+*/
+import "C"
+import (
+	"errors"
+	"unsafe"
+)
+
+// Sentinel errors describing the category of a failure. Errors returned by
+// this package wrap one of them when the category is known, so callers can
+// test them with errors.Is.
+var (
+	ErrNotFound        = errors.New("entry not found")
+	ErrInvalidArchive  = errors.New("invalid or corrupted archive")
+	ErrDuplicatePath   = errors.New("duplicate path")
+	ErrNoFulltextIndex = errors.New("archive has no fulltext index")
+	ErrNoChecksum      = errors.New("archive has no checksum")
+	ErrCreatorState    = errors.New("invalid creator state")
+	ErrIO              = errors.New("i/o error")
+	ErrRedirectLoop    = errors.New("redirect loop")
+	ErrClosed          = errors.New("use of closed handle")
+)
+
+// Error is returned when a libzim call fails. It carries the operation that
+// failed, the error category and the message of the underlying C++ exception.
+type Error struct {
+	Op      string // Go method that failed, e.g. "GetEntryByPath"
+	Kind    error  // One of the sentinel errors, or nil when unclassified
+	Message string // libzim exception message, may be empty
+}
+
+func (e *Error) Error() string {
+	msg := e.Message
+	if msg == "" && e.Kind != nil {
+		msg = e.Kind.Error()
+	}
+	if msg == "" {
+		msg = "unknown error"
+	}
+	return e.Op + ": " + msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+// newError converts the error reported by the C layer into an *Error and frees
+// its message. fallback is used when the C layer failed without reporting a
+// category.
+func newError(op string, cerr *C.zim_error_t, fallback error) error {
+	e := &Error{Op: op, Kind: fallback}
+	if cerr.message != nil {
+		e.Message = C.GoString(cerr.message)
+		C.free(unsafe.Pointer(cerr.message))
+		cerr.message = nil
+	}
+
+	switch cerr.code {
+	case C.ZIM_ERR_NOT_FOUND:
+		e.Kind = ErrNotFound
+	case C.ZIM_ERR_INVALID_ARCHIVE:
+		e.Kind = ErrInvalidArchive
+	case C.ZIM_ERR_DUPLICATE_PATH:
+		e.Kind = ErrDuplicatePath
+	case C.ZIM_ERR_NO_FULLTEXT_INDEX:
+		e.Kind = ErrNoFulltextIndex
+	case C.ZIM_ERR_CREATOR_STATE:
+		e.Kind = ErrCreatorState
+	case C.ZIM_ERR_IO:
+		e.Kind = ErrIO
+	case C.ZIM_ERR_UNKNOWN:
+		e.Kind = nil
+	}
+	return e
+}
@@ -0,0 +1,156 @@
+package zim
+
+import (
+	"cmp"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// handleSeq numbers handles so that parents are always locked in the same order
+var handleSeq atomic.Uint64
+
+// handle guards the C++ object owned by a Go wrapper. Every call into C holds
+// a read lock on the handle and on its parents, so an object (or the archive
+// it comes from) cannot be freed while in use, and calls made after Close
+// fail with ErrClosed instead of passing a dangling pointer to C.
+//
+// The handle is allocated apart from its wrapper so that the cleanup attached
+// to the wrapper can reach it without keeping the wrapper alive.
+type handle struct {
+	mu      sync.RWMutex
+	id      uint64
+	closed  bool
+	free    func()      // Releases the C++ object, must not reference the wrapper
+	parents []*handle   // Sorted by id, guarded by mu
+	leak    *leakRecord // Non-nil when leak tracking was enabled at creation
+}
+
+// newHandle returns the handle of a wrapper of the given type. free is run
+// once, by Close or by the garbage collector cleanup registered with track.
+func newHandle(kind string, free func(), parents ...*handle) *handle {
+	h := &handle{id: handleSeq.Add(1), free: free}
+	for _, p := range parents {
+		h.insertParent(p)
+	}
+	h.leak = trackHandle(kind)
+	return h
+}
+
+// insertParent adds p to the sorted parents unless already present. Keeping
+// a single order avoids deadlocks between handles sharing several parents.
+// Once h is in use, it must be called with h locked.
+func (h *handle) insertParent(p *handle) {
+	i, found := slices.BinarySearchFunc(h.parents, p.id, func(q *handle, id uint64) int {
+		return cmp.Compare(q.id, id)
+	})
+	if !found {
+		h.parents = slices.Insert(h.parents, i, p)
+	}
+}
+
+// track frees the handle when obj becomes unreachable without being closed
+func track[T any](obj *T, h *handle) {
+	runtime.AddCleanup(obj, (*handle).collect, h)
+}
+
+// acquire read-locks h then its parents. It returns false, holding no lock,
+// if any of them has been closed.
+func (h *handle) acquire() bool {
+	h.mu.RLock()
+	if h.closed {
+		h.mu.RUnlock()
+		return false
+	}
+
+	for i, p := range h.parents {
+		if !p.acquire() {
+			for _, q := range h.parents[:i] {
+				q.release()
+			}
+			h.mu.RUnlock()
+			return false
+		}
+	}
+	return true
+}
+
+// acquireAll acquires the distinct handles of hs, in id order. It returns a
+// function releasing them, or nil, holding no lock, if one of them is closed.
+func acquireAll(hs ...*handle) func() {
+	hs = slices.Clone(hs)
+	slices.SortFunc(hs, func(a, b *handle) int { return cmp.Compare(a.id, b.id) })
+	hs = slices.Compact(hs)
+
+	for i, h := range hs {
+		if !h.acquire() {
+			for _, q := range hs[:i] {
+				q.release()
+			}
+			return nil
+		}
+	}
+	return func() {
+		for _, h := range hs {
+			h.release()
+		}
+	}
+}
+
+// lock gives exclusive use of h, for calls that modify the object. It
+// returns false, holding no lock, if h is closed.
+func (h *handle) lock() bool {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return false
+	}
+	return true
+}
+
+// unlock undoes a successful lock
+func (h *handle) unlock() {
+	h.mu.Unlock()
+}
+
+// release undoes a successful acquire
+func (h *handle) release() {
+	for _, p := range h.parents {
+		p.release()
+	}
+	h.mu.RUnlock()
+}
+
+// close frees the object once, after the calls in flight have returned. It
+// is safe to call concurrently and repeatedly.
+func (h *handle) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return
+	}
+	h.closed = true
+	h.free()
+	untrackHandle(h.leak)
+}
+
+// collect is the garbage collector cleanup. Unlike close, it leaves the leak
+// record in place: a handle that was never closed is what tracking reports.
+func (h *handle) collect() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return
+	}
+	h.closed = true
+	h.free()
+	collectHandle(h.leak)
+}
+
+// closedError is returned by methods called on a closed handle
+func closedError(op string) error {
+	return &Error{Op: op, Kind: ErrClosed}
+}
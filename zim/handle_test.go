@@ -0,0 +1,148 @@
+package zim
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"testing"
+)
+
+func TestHandle_CloseOnce(t *testing.T) {
+	frees := 0
+	h := newHandle("Test", func() { frees++ })
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h.close()
+		}()
+	}
+	wg.Wait()
+
+	if frees != 1 {
+		t.Errorf("Expected free to run once, ran %d times", frees)
+	}
+	if h.acquire() {
+		t.Error("Expected acquire to fail on a closed handle")
+	}
+}
+
+func TestHandle_ClosedParent(t *testing.T) {
+	parent := newHandle("Test", func() {})
+	child := newHandle("Test", func() {}, parent, parent)
+	if len(child.parents) != 1 {
+		t.Fatalf("Expected duplicate parents to be dropped, got %d", len(child.parents))
+	}
+
+	if !child.acquire() {
+		t.Fatal("Expected acquire to succeed")
+	}
+	child.release()
+
+	parent.close()
+	if child.acquire() {
+		t.Fatal("Expected acquire to fail once the parent is closed")
+	}
+	// A failed acquire must not leave read locks behind
+	if !child.mu.TryLock() {
+		t.Fatal("Expected child lock to be free after a failed acquire")
+	}
+	child.mu.Unlock()
+	child.close()
+}
+
+func TestArchive_UseAfterClose(t *testing.T) {
+	path := createTestArchive(t, "index.html",
+		testItem{path: "index.html", mimetype: "text/html", title: "Home", content: "<p>home</p>", front: true},
+	)
+	archive, err := NewArchive(path)
+	if err != nil {
+		t.Fatalf("Failed to open archive: %v", err)
+	}
+
+	entry, err := archive.GetEntryByPath("index.html")
+	if err != nil {
+		t.Fatalf("Failed to get entry: %v", err)
+	}
+	item, err := entry.GetItem(true)
+	if err != nil {
+		t.Fatalf("Failed to get item: %v", err)
+	}
+
+	archive.Close()
+	archive.Close()
+
+	if _, err := archive.GetEntryByPath("index.html"); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed from closed archive, got %v", err)
+	}
+	if archive.GetEntryCount() != 0 {
+		t.Error("Expected zero entry count from closed archive")
+	}
+	if _, err := entry.GetItem(true); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed from entry of closed archive, got %v", err)
+	}
+	if entry.Path() != "" {
+		t.Error("Expected empty path from entry of closed archive")
+	}
+	if _, err := item.GetBlob(); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed from item of closed archive, got %v", err)
+	}
+	if _, err := item.Reader().ReadAt(make([]byte, 4), 0); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed from reader of closed archive, got %v", err)
+	}
+	if _, err := io.ReadAll(item.Reader()); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed reading an item of closed archive, got %v", err)
+	}
+	if _, err := entry.RedirectChain(); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed from redirect chain of closed archive, got %v", err)
+	}
+	for _, err := range archive.EntriesByPath() {
+		if !errors.Is(err, ErrClosed) {
+			t.Errorf("Expected ErrClosed from iterator of closed archive, got %v", err)
+		}
+	}
+
+	item.Close()
+	item.Close()
+	entry.Close()
+	entry.Close()
+}
+
+func TestEntry_UseAfterClose(t *testing.T) {
+	archive, err := NewArchive(getTestZimPath())
+	if err != nil {
+		t.Fatalf("Failed to open valid ZIM archive: %v", err)
+	}
+	defer archive.Close()
+
+	entry, err := archive.GetMainEntry()
+	if err != nil {
+		t.Fatalf("Failed to get main entry: %v", err)
+	}
+	entry.Close()
+
+	if _, err := entry.GetItem(true); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed from closed entry, got %v", err)
+	}
+	if _, err := entry.RedirectChain(); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed from redirect chain of closed entry, got %v", err)
+	}
+	// The archive itself stays usable
+	if archive.GetEntryCount() == 0 {
+		t.Error("Expected archive to remain usable after closing an entry")
+	}
+}
+
+func TestCreator_UseAfterClose(t *testing.T) {
+	creator, err := NewCreator()
+	if err != nil {
+		t.Fatalf("Failed to create ZIM Creator: %v", err)
+	}
+	creator.Close()
+	creator.Close()
+
+	if err := creator.StartZimCreation(t.TempDir() + "/closed.zim"); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed from closed creator, got %v", err)
+	}
+}
@@ -1,20 +1,36 @@
 package zim
 
 import (
-	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"os"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
+// maxIdleArchiveFiles bounds the descriptors kept open per archive file to
+// serve uncompressed content
+const maxIdleArchiveFiles = 16
+
 // HTTPServer is a native Go HTTP handler that serves files directly from a ZIM archive
 type HTTPServer struct {
 	Archive *Archive
+	// Resolve selects the fallbacks used when a request path matches no entry
+	Resolve ResolveOptions
+
+	mu    sync.Mutex
+	files map[string][]*os.File // Idle descriptors of the archive files, by name
 }
 
-// NewHTTPServer creates a new native Go HTTP server for a ZIM archive
+// NewHTTPServer creates a new native Go HTTP server for a ZIM archive, with
+// case-insensitive and title fallbacks enabled
 func NewHTTPServer(archive *Archive) *HTTPServer {
 	return &HTTPServer{
 		Archive: archive,
+		Resolve: ResolveOptions{CaseInsensitive: true, TitleFallback: true},
 	}
 }
 
@@ -34,8 +50,8 @@ func (s *HTTPServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			entry, err = s.Archive.GetEntryByPath("index.html")
 		}
 	} else {
-		// Serve a specific file
-		entry, err = s.Archive.GetEntryByPath(path)
+		// Serve a specific file, tolerating legacy namespaces and odd encodings
+		entry, err = s.Archive.ResolvePathWithOptions(path, s.Resolve)
 	}
 
 	if err != nil {
@@ -44,6 +60,15 @@ func (s *HTTPServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 	defer entry.Close()
 
+	// Send fallback matches to the entry path, so relative links resolve
+	// against the right directory. The target is relative, which keeps the
+	// client under the prefix the server is mounted at.
+	if path != "" && entry.Path() != path {
+		w.Header().Set("Location", relativeLocation(path, entry.Path()))
+		w.WriteHeader(http.StatusFound)
+		return
+	}
+
 	// Follow redirects (e.g. if the entry is just a pointer to another entry)
 	item, err := entry.GetItem(true)
 	if err != nil {
@@ -52,21 +77,110 @@ func (s *HTTPServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 	defer item.Close()
 
-	// Get data and metadata
-	data := item.GetData()
-	mimetype := item.GetMimetype()
-	size := item.GetSize()
-
 	// Set required HTTP headers
-	if mimetype != "" {
+	if mimetype := item.GetMimetype(); mimetype != "" {
 		w.Header().Set("Content-Type", mimetype)
 	}
-	w.Header().Set("Content-Length", fmt.Sprintf("%d", size))
 
 	// Ensure caching headers are set since ZIM content is static
 	w.Header().Set("Cache-Control", "public, max-age=86400")
 
-	// Write the payload to the HTTP response
+	// Uncompressed content is read straight from the archive file without going through cgo
+	if file, offset, ok := item.DirectAccess(); ok {
+		if s.serveDirect(w, r, path, file, offset, int64(item.GetSize())) {
+			return
+		}
+	}
+
+	// Stream the payload, ServeContent takes care of Content-Length and Range requests
+	http.ServeContent(w, r, path, time.Time{}, item.Reader())
+}
+
+// Close closes the archive files kept open to serve uncompressed content.
+// It does not close the Archive.
+func (s *HTTPServer) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var firstErr error
+	for _, files := range s.files {
+		for _, f := range files {
+			if err := f.Close(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	s.files = nil
+	return firstErr
+}
+
+// relativeLocation returns a link from the request path to the entry path,
+// both relative to the root of the server
+func relativeLocation(path, entryPath string) string {
+	up := strings.Repeat("../", strings.Count(path, "/"))
+	if up == "" {
+		// Keeps a colon in the first segment from being read as a scheme
+		up = "./"
+	}
+	target := url.URL{Path: entryPath}
+	return up + target.EscapedPath()
+}
+
+// serveDirect serves size bytes at offset of file. Plain GETs copy from the
+// file itself so that the kernel can use sendfile. It returns false if the
+// file cannot be used, in which case nothing has been written.
+func (s *HTTPServer) serveDirect(w http.ResponseWriter, r *http.Request, name, file string, offset, size int64) bool {
+	f, err := s.openFile(file)
+	if err != nil {
+		return false
+	}
+	defer s.releaseFile(file, f)
+
+	if r.Header.Get("Range") != "" {
+		http.ServeContent(w, r, name, time.Time{}, io.NewSectionReader(f, offset, size))
+		return true
+	}
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return false
+	}
+	w.Header().Set("Accept-Ranges", "bytes")
+	w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
 	w.WriteHeader(http.StatusOK)
-	w.Write(data)
+	if r.Method != http.MethodHead {
+		// The copy goes through an *io.LimitedReader over the *os.File,
+		// which the connection hands to sendfile
+		io.CopyN(w, f, size)
+	}
+	return true
+}
+
+// openFile returns an idle descriptor of the archive file, opening one if
+// every descriptor is in use by another request
+func (s *HTTPServer) openFile(name string) (*os.File, error) {
+	s.mu.Lock()
+	if idle := s.files[name]; len(idle) > 0 {
+		f := idle[len(idle)-1]
+		s.files[name] = idle[:len(idle)-1]
+		s.mu.Unlock()
+		return f, nil
+	}
+	s.mu.Unlock()
+
+	return os.Open(name)
+}
+
+// releaseFile hands a descriptor back for the next requests
+func (s *HTTPServer) releaseFile(name string, f *os.File) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.files == nil {
+		s.files = map[string][]*os.File{}
+	}
+	if len(s.files[name]) >= maxIdleArchiveFiles {
+		f.Close()
+		return
+	}
+	s.files[name] = append(s.files[name], f)
 }
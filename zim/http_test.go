@@ -86,3 +86,155 @@ func TestNativeHTTPServer_ServeNotFound(t *testing.T) {
 		t.Errorf("Expected status Not Found (404), got %v", resp.StatusCode)
 	}
 }
+
+func TestNativeHTTPServer_RangeRequest(t *testing.T) {
+	content := "0123456789abcdefghij"
+	path := createTestArchive(t, "",
+		testItem{path: "data.txt", mimetype: "text/plain", title: "Data", content: content},
+	)
+
+	archive, err := NewArchive(path)
+	if err != nil {
+		t.Fatalf("Failed to open generated ZIM archive: %v", err)
+	}
+	defer archive.Close()
+
+	zimServer := NewHTTPServer(archive)
+	defer zimServer.Close()
+
+	req := httptest.NewRequest(http.MethodGet, "/data.txt", nil)
+	req.Header.Set("Range", "bytes=10-14")
+	w := httptest.NewRecorder()
+	zimServer.ServeHTTP(w, req)
+
+	resp := w.Result()
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusPartialContent {
+		t.Fatalf("Expected status Partial Content (206), got %v", resp.StatusCode)
+	}
+	if body := w.Body.String(); body != content[10:15] {
+		t.Errorf("Expected body %q, got %q", content[10:15], body)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/plain" {
+		t.Errorf("Expected Content-Type text/plain, got %q", ct)
+	}
+}
+
+func TestNativeHTTPServer_DirectGet(t *testing.T) {
+	content := "0123456789abcdefghij"
+	path := createTestArchive(t, "",
+		testItem{path: "data.txt", mimetype: "text/plain", title: "Data", content: content},
+	)
+
+	archive, err := NewArchive(path)
+	if err != nil {
+		t.Fatalf("Failed to open generated ZIM archive: %v", err)
+	}
+	defer archive.Close()
+
+	zimServer := NewHTTPServer(archive)
+	defer zimServer.Close()
+
+	// Twice, so the second request reuses the descriptor of the first
+	for range 2 {
+		req := httptest.NewRequest(http.MethodGet, "/data.txt", nil)
+		w := httptest.NewRecorder()
+		zimServer.ServeHTTP(w, req)
+
+		resp := w.Result()
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status OK (200), got %v", resp.StatusCode)
+		}
+		if resp.ContentLength != int64(len(content)) {
+			t.Errorf("Expected Content-Length %d, got %d", len(content), resp.ContentLength)
+		}
+		if body := w.Body.String(); body != content {
+			t.Errorf("Expected body %q, got %q", content, body)
+		}
+	}
+}
+
+func TestNativeHTTPServer_RedirectToEntryPath(t *testing.T) {
+	path := createTestArchive(t, "",
+		testItem{path: "wiki/Paris", mimetype: "text/html", title: "City of Paris", content: "<p>paris</p>", front: true},
+	)
+
+	archive, err := NewArchive(path)
+	if err != nil {
+		t.Fatalf("Failed to open generated ZIM archive: %v", err)
+	}
+	defer archive.Close()
+
+	zimServer := NewHTTPServer(archive)
+	defer zimServer.Close()
+
+	// At the root and mounted under a prefix, the redirect must stay in the mount
+	mounts := map[string]http.Handler{
+		"":        zimServer,
+		"/mirror": http.StripPrefix("/mirror", zimServer),
+	}
+	for mount, handler := range mounts {
+		for _, target := range []string{"/wiki/paris", "/wiki/City_of_Paris", "/A/wiki/Paris"} {
+			req := httptest.NewRequest(http.MethodGet, mount+target, nil)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			resp := w.Result()
+			resp.Body.Close()
+
+			if resp.StatusCode != http.StatusFound {
+				t.Errorf("%s%s: expected status Found (302), got %v", mount, target, resp.StatusCode)
+				continue
+			}
+			loc, err := req.URL.Parse(resp.Header.Get("Location"))
+			if err != nil {
+				t.Fatalf("%s%s: invalid redirect: %v", mount, target, err)
+			}
+			if want := mount + "/wiki/Paris"; loc.Path != want {
+				t.Errorf("%s%s: expected redirect to %s, got %s", mount, target, want, loc.Path)
+			}
+		}
+
+		req := httptest.NewRequest(http.MethodGet, mount+"/wiki/Paris", nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status OK (200) for the entry path under %q, got %v", mount, w.Code)
+		}
+	}
+}
+
+func TestNativeHTTPServer_PercentPath(t *testing.T) {
+	path := createTestArchive(t, "",
+		testItem{path: "100%.html", mimetype: "text/html", title: "Percent", content: "percent"},
+		testItem{path: "100%25.html", mimetype: "text/html", title: "Escaped percent", content: "escaped"},
+	)
+
+	archive, err := NewArchive(path)
+	if err != nil {
+		t.Fatalf("Failed to open generated ZIM archive: %v", err)
+	}
+	defer archive.Close()
+
+	zimServer := NewHTTPServer(archive)
+	defer zimServer.Close()
+
+	// The request path is decoded once by net/http and must not be decoded again
+	cases := map[string]string{"/100%25.html": "percent", "/100%2525.html": "escaped"}
+	for target, want := range cases {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		w := httptest.NewRecorder()
+		zimServer.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: expected status OK (200), got %v", target, w.Code)
+			continue
+		}
+		if body := w.Body.String(); body != want {
+			t.Errorf("%s: expected body %q, got %q", target, want, body)
+		}
+	}
+}
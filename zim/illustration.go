@@ -0,0 +1,143 @@
+package zim
+
+/*
+#include <stdlib.h>
+#include "zim_wrapper.h"
+*/
+import "C"
+import "unsafe"
+
+// IllustrationInfo describes one of the illustrations (book icons) stored in
+// an archive. Scale is 1 for regular illustrations and 2 for HiDPI variants.
+type IllustrationInfo struct {
+	Width  uint
+	Height uint
+	Scale  float32
+}
+
+// Illustration is an archive illustration together with its content
+type Illustration struct {
+	IllustrationInfo
+	Mimetype string
+	Data     []byte
+}
+
+// IllustrationSizes lists the sizes of the square, unscaled illustrations in
+// the archive, as written by Creator.AddIllustration.
+func (a *Archive) IllustrationSizes() ([]uint, error) {
+	if !a.h.acquire() {
+		return nil, closedError("IllustrationSizes")
+	}
+	defer a.h.release()
+
+	var cerr C.zim_error_t
+	var count C.int
+	cSizes := C.zim_archive_get_illustration_sizes(a.ptr, &count, &cerr)
+	if cSizes == nil {
+		if cerr.code == C.ZIM_OK {
+			return []uint{}, nil
+		}
+		return nil, newError("IllustrationSizes", &cerr, nil)
+	}
+	defer C.free(unsafe.Pointer(cSizes))
+
+	sizes := make([]uint, int(count))
+	for i, size := range unsafe.Slice(cSizes, int(count)) {
+		sizes[i] = uint(size)
+	}
+	return sizes, nil
+}
+
+// GetIllustration returns the square illustration of the given size (e.g. 48).
+// It returns ErrNotFound if the archive has no illustration of that size.
+func (a *Archive) GetIllustration(size uint) (*Illustration, error) {
+	if !a.h.acquire() {
+		return nil, closedError("GetIllustration")
+	}
+	defer a.h.release()
+
+	var cerr C.zim_error_t
+	ptr := C.zim_archive_get_illustration_item(a.ptr, C.uint(size), &cerr)
+	if ptr == nil {
+		return nil, newError("GetIllustration", &cerr, ErrNotFound)
+	}
+
+	info := IllustrationInfo{Width: size, Height: size, Scale: 1}
+	return newIllustration("GetIllustration", info, ptr)
+}
+
+// IllustrationInfos lists every illustration in the archive, including
+// non-square and scaled ones. Built against a libzim without
+// zim/illustration.h, it only lists the square, unscaled ones.
+func (a *Archive) IllustrationInfos() ([]IllustrationInfo, error) {
+	if !a.h.acquire() {
+		return nil, closedError("IllustrationInfos")
+	}
+	defer a.h.release()
+
+	var cerr C.zim_error_t
+	var count C.int
+	cInfos := C.zim_archive_get_illustration_infos(a.ptr, &count, &cerr)
+	if cInfos == nil {
+		if cerr.code == C.ZIM_OK {
+			return []IllustrationInfo{}, nil
+		}
+		return nil, newError("IllustrationInfos", &cerr, nil)
+	}
+	defer C.free(unsafe.Pointer(cInfos))
+
+	infos := make([]IllustrationInfo, int(count))
+	for i, ci := range unsafe.Slice(cInfos, int(count)) {
+		infos[i] = IllustrationInfo{
+			Width:  uint(ci.width),
+			Height: uint(ci.height),
+			Scale:  float32(ci.scale),
+		}
+	}
+	return infos, nil
+}
+
+// GetIllustrationByInfo returns the illustration matching info, as listed by
+// IllustrationInfos. It returns ErrNotFound for non-square or scaled
+// illustrations when libzim does not support them.
+func (a *Archive) GetIllustrationByInfo(info IllustrationInfo) (*Illustration, error) {
+	if !a.h.acquire() {
+		return nil, closedError("GetIllustrationByInfo")
+	}
+	defer a.h.release()
+
+	cInfo := C.zim_illustration_info_t{
+		width:  C.uint32_t(info.Width),
+		height: C.uint32_t(info.Height),
+		scale:  C.float(info.Scale),
+	}
+
+	var cerr C.zim_error_t
+	ptr := C.zim_archive_get_illustration_item_by_info(a.ptr, cInfo, &cerr)
+	if ptr == nil {
+		return nil, newError("GetIllustrationByInfo", &cerr, ErrNotFound)
+	}
+
+	return newIllustration("GetIllustrationByInfo", info, ptr)
+}
+
+// newIllustration reads the illustration content and frees the item
+func newIllustration(op string, info IllustrationInfo, ptr C.zim_item_t) (*Illustration, error) {
+	defer C.zim_item_free(ptr)
+
+	ill := &Illustration{IllustrationInfo: info}
+	if cMime := C.zim_item_get_mimetype(ptr); cMime != nil {
+		ill.Mimetype = C.GoString(cMime)
+		C.free(unsafe.Pointer(cMime))
+	}
+
+	var cerr C.zim_error_t
+	blob := C.zim_item_get_blob(ptr, &cerr)
+	if blob == nil {
+		return nil, newError(op, &cerr, nil)
+	}
+	defer C.zim_blob_free(blob)
+
+	ill.Data = C.GoBytes(unsafe.Pointer(C.zim_blob_data(blob)), C.int(C.zim_blob_size(blob)))
+	return ill, nil
+}
@@ -0,0 +1,70 @@
+package zim
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// A 1x1 transparent PNG
+var testPNG = []byte{
+	0x89, 0x50, 0x4e, 0x47, 0x0d, 0x0a, 0x1a, 0x0a, 0x00, 0x00, 0x00, 0x0d,
+	0x49, 0x48, 0x44, 0x52, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01,
+	0x08, 0x06, 0x00, 0x00, 0x00, 0x1f, 0x15, 0xc4, 0x89, 0x00, 0x00, 0x00,
+	0x0d, 0x49, 0x44, 0x41, 0x54, 0x78, 0x9c, 0x63, 0x00, 0x01, 0x00, 0x00,
+	0x05, 0x00, 0x01, 0x0d, 0x0a, 0x2d, 0xb4, 0x00, 0x00, 0x00, 0x00, 0x49,
+	0x45, 0x4e, 0x44, 0xae, 0x42, 0x60, 0x82,
+}
+
+func TestArchive_Illustrations(t *testing.T) {
+	outPath := filepath.Join(t.TempDir(), "illustration.zim")
+
+	creator, err := NewCreator()
+	if err != nil {
+		t.Fatalf("Failed to create ZIM Creator: %v", err)
+	}
+	defer creator.Close()
+
+	creator.ConfigCompression(CompressionNone)
+	if err := creator.StartZimCreation(outPath); err != nil {
+		t.Fatalf("Failed to start creation: %v", err)
+	}
+	if err := creator.AddIllustration(48, testPNG); err != nil {
+		t.Fatalf("Failed to add illustration: %v", err)
+	}
+	if err := creator.FinishZimCreation(); err != nil {
+		t.Fatalf("Failed to finish ZIM creation: %v", err)
+	}
+
+	archive, err := NewArchive(outPath)
+	if err != nil {
+		t.Fatalf("Failed to open generated ZIM archive: %v", err)
+	}
+	defer archive.Close()
+
+	sizes, err := archive.IllustrationSizes()
+	if err != nil {
+		t.Fatalf("Failed to list illustration sizes: %v", err)
+	}
+	if !slices.Contains(sizes, 48) {
+		t.Fatalf("Expected illustration sizes to contain 48, got %v", sizes)
+	}
+
+	ill, err := archive.GetIllustration(48)
+	if err != nil {
+		t.Fatalf("Failed to get illustration: %v", err)
+	}
+	if ill.Mimetype != "image/png" {
+		t.Errorf("Expected mimetype image/png, got %q", ill.Mimetype)
+	}
+	if !bytes.Equal(ill.Data, testPNG) {
+		t.Errorf("Illustration content mismatch")
+	}
+
+	_, err = archive.GetIllustration(96)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for missing illustration size, got %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package zim
+
+/*
+#include <stdlib.h>
+#include "zim_wrapper.h"
+*/
+import "C"
+import (
+	"iter"
+	"unsafe"
+)
+
+// entryBatchSize is the number of entries fetched from libzim per cgo call
+const entryBatchSize = 64
+
+// EntriesByPath iterates over all user entries sorted by path.
+// Iteration stops after the first error.
+func (a *Archive) EntriesByPath() iter.Seq2[*Entry, error] {
+	return a.iterEntries("EntriesByPath", func(cerr *C.zim_error_t) C.zim_entry_cursor_t {
+		return C.zim_archive_iter_by_path(a.ptr, cerr)
+	})
+}
+
+// EntriesByTitle iterates over all user entries sorted by title.
+// Iteration stops after the first error.
+func (a *Archive) EntriesByTitle() iter.Seq2[*Entry, error] {
+	return a.iterEntries("EntriesByTitle", func(cerr *C.zim_error_t) C.zim_entry_cursor_t {
+		return C.zim_archive_iter_by_title(a.ptr, cerr)
+	})
+}
+
+// EntriesInClusterOrder iterates over all user entries in the order their
+// content is stored in the archive. Reading items in this order decompresses
+// each cluster only once, which makes full dumps much faster.
+// Iteration stops after the first error.
+func (a *Archive) EntriesInClusterOrder() iter.Seq2[*Entry, error] {
+	return a.iterEntries("EntriesInClusterOrder", func(cerr *C.zim_error_t) C.zim_entry_cursor_t {
+		return C.zim_archive_iter_efficient(a.ptr, cerr)
+	})
+}
+
+// FindByPathPrefix iterates over the entries whose path starts with prefix,
+// sorted by path (e.g. all "_res/" assets).
+func (a *Archive) FindByPathPrefix(prefix string) iter.Seq2[*Entry, error] {
+	return a.iterEntries("FindByPathPrefix", func(cerr *C.zim_error_t) C.zim_entry_cursor_t {
+		cPrefix := C.CString(prefix)
+		defer C.free(unsafe.Pointer(cPrefix))
+		return C.zim_archive_find_by_path(a.ptr, cPrefix, cerr)
+	})
+}
+
+// FindByTitlePrefix iterates over the front articles whose title starts with
+// prefix, sorted by title. The match is case sensitive.
+func (a *Archive) FindByTitlePrefix(prefix string) iter.Seq2[*Entry, error] {
+	return a.iterEntries("FindByTitlePrefix", func(cerr *C.zim_error_t) C.zim_entry_cursor_t {
+		cPrefix := C.CString(prefix)
+		defer C.free(unsafe.Pointer(cPrefix))
+		return C.zim_archive_find_by_title(a.ptr, cPrefix, cerr)
+	})
+}
+
+// iterEntries walks the cursor returned by open, fetching entries in batches.
+// The archive is only held during the cgo calls, never while yielding, so the
+// loop body may use it freely. The yielded entries are owned by the caller.
+func (a *Archive) iterEntries(op string, open func(*C.zim_error_t) C.zim_entry_cursor_t) iter.Seq2[*Entry, error] {
+	return func(yield func(*Entry, error) bool) {
+		if !a.h.acquire() {
+			yield(nil, closedError(op))
+			return
+		}
+		var cerr C.zim_error_t
+		cursor := open(&cerr)
+		a.h.release()
+		if cursor == nil {
+			yield(nil, newError(op, &cerr, nil))
+			return
+		}
+		defer C.zim_entry_cursor_free(cursor)
+
+		var batch [entryBatchSize]C.zim_entry_t
+		for {
+			if !a.h.acquire() {
+				yield(nil, closedError(op))
+				return
+			}
+			n := int(C.zim_entry_cursor_next_batch(cursor, &batch[0], C.int(len(batch)), &cerr))
+			a.h.release()
+
+			for i := 0; i < n; i++ {
+				if !yield(a.newEntry(batch[i]), nil) {
+					// Release the entries fetched but never handed out
+					for _, ptr := range batch[i+1 : n] {
+						C.zim_entry_free(ptr)
+					}
+					// and the error the batch ended with, if any
+					if cerr.message != nil {
+						C.free(unsafe.Pointer(cerr.message))
+					}
+					return
+				}
+			}
+
+			if cerr.code != C.ZIM_OK {
+				yield(nil, newError(op, &cerr, nil))
+				return
+			}
+			if n == 0 {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package zim
+
+import (
+	"iter"
+	"testing"
+)
+
+func TestArchive_EntryIterators(t *testing.T) {
+	archive, err := NewArchive(getTestZimPath())
+	if err != nil {
+		t.Fatalf("Failed to open valid ZIM archive: %v", err)
+	}
+	defer archive.Close()
+
+	want := archive.GetEntryCount()
+
+	iterators := map[string]func() int{
+		"EntriesByPath":         func() int { return countEntries(t, archive.EntriesByPath()) },
+		"EntriesByTitle":        func() int { return countEntries(t, archive.EntriesByTitle()) },
+		"EntriesInClusterOrder": func() int { return countEntries(t, archive.EntriesInClusterOrder()) },
+	}
+	for name, count := range iterators {
+		if got := count(); uint64(got) != want {
+			t.Errorf("%s: expected %d entries, got %d", name, want, got)
+		}
+	}
+}
+
+func TestArchive_EntryIteratorBreak(t *testing.T) {
+	archive, err := NewArchive(getTestZimPath())
+	if err != nil {
+		t.Fatalf("Failed to open valid ZIM archive: %v", err)
+	}
+	defer archive.Close()
+
+	if archive.GetEntryCount() < 2 {
+		t.Skip("Skipping break test because the test ZIM has fewer than 2 entries.")
+	}
+
+	seen := 0
+	for entry, err := range archive.EntriesByPath() {
+		if err != nil {
+			t.Fatalf("Iteration failed: %v", err)
+		}
+		entry.Close()
+		seen++
+		if seen == 1 {
+			break
+		}
+	}
+	if seen != 1 {
+		t.Errorf("Expected iteration to stop after 1 entry, got %d", seen)
+	}
+}
+
+func countEntries(t *testing.T, seq iter.Seq2[*Entry, error]) int {
+	t.Helper()
+	n := 0
+	for entry, err := range seq {
+		if err != nil {
+			t.Fatalf("Iteration failed: %v", err)
+		}
+		entry.Close()
+		n++
+	}
+	return n
+}
+
+func TestArchive_FindByPrefix(t *testing.T) {
+	path := createTestArchive(t, "index.html",
+		testItem{path: "index.html", mimetype: "text/html", title: "Home", content: "<html></html>", front: true},
+		testItem{path: "apple.html", mimetype: "text/html", title: "Apple", content: "<html></html>", front: true},
+		testItem{path: "apricot.html", mimetype: "text/html", title: "Apricot", content: "<html></html>", front: true},
+		testItem{path: "banana.html", mimetype: "text/html", title: "Banana", content: "<html></html>", front: true},
+		testItem{path: "_res/a.css", mimetype: "text/css", content: "a{}"},
+		testItem{path: "_res/b.css", mimetype: "text/css", content: "b{}"},
+	)
+
+	archive, err := NewArchive(path)
+	if err != nil {
+		t.Fatalf("Failed to open generated ZIM archive: %v", err)
+	}
+	defer archive.Close()
+
+	if got := countEntries(t, archive.FindByPathPrefix("_res/")); got != 2 {
+		t.Errorf("Expected 2 entries under _res/, got %d", got)
+	}
+	if got := countEntries(t, archive.FindByTitlePrefix("Ap")); got != 2 {
+		t.Errorf("Expected 2 titles starting with Ap, got %d", got)
+	}
+	if got := countEntries(t, archive.FindByPathPrefix("nothing/")); got != 0 {
+		t.Errorf("Expected no entries under nothing/, got %d", got)
+	}
+}
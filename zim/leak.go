@@ -0,0 +1,164 @@
+package zim
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// leakTrackingEnv enables leak tracking at startup when set to a true value
+// (e.g. ZIM_LEAK_TRACKING=1)
+const leakTrackingEnv = "ZIM_LEAK_TRACKING"
+
+var (
+	leakTracking atomic.Bool
+
+	leakMu   sync.Mutex
+	leakNext uint64
+	leaks    = map[uint64]*leakRecord{}
+)
+
+func init() {
+	if on, _ := strconv.ParseBool(os.Getenv(leakTrackingEnv)); on {
+		EnableLeakTracking()
+	}
+}
+
+// leakRecord describes a tracked handle
+type leakRecord struct {
+	id        uint64
+	kind      string
+	stack     string
+	collected bool
+}
+
+// Leak describes a handle that was created while leak tracking was enabled
+// and has not been closed
+type Leak struct {
+	Type      string // Wrapper type, e.g. "Entry"
+	Stack     string // Stack trace of the call that created it
+	Collected bool   // Freed by the garbage collector instead of Close
+}
+
+// EnableLeakTracking records every Archive, Entry, Item, Search, Creator and
+// other libzim handle created from now on, along with the stack trace of its
+// creation, until it is closed. Setting the ZIM_LEAK_TRACKING environment
+// variable to 1 enables it at startup. Tracking slows down handle creation
+// and is meant for tests and debugging.
+func EnableLeakTracking() {
+	leakTracking.Store(true)
+}
+
+// DisableLeakTracking stops recording new handles and forgets the recorded ones
+func DisableLeakTracking() {
+	leakTracking.Store(false)
+
+	leakMu.Lock()
+	defer leakMu.Unlock()
+	clear(leaks)
+}
+
+// LiveHandles returns the number of tracked handles not closed yet, by type
+func LiveHandles() map[string]int {
+	leakMu.Lock()
+	defer leakMu.Unlock()
+
+	counts := map[string]int{}
+	for _, rec := range leaks {
+		counts[rec.kind]++
+	}
+	return counts
+}
+
+// Leaks lists the tracked handles not closed yet, oldest first. Handles
+// reclaimed by the garbage collector are still listed, since they were never
+// closed.
+func Leaks() []Leak {
+	leakMu.Lock()
+	recs := make([]leakRecord, 0, len(leaks))
+	for _, rec := range leaks {
+		recs = append(recs, *rec)
+	}
+	leakMu.Unlock()
+
+	slices.SortFunc(recs, func(a, b leakRecord) int { return cmp.Compare(a.id, b.id) })
+	out := make([]Leak, len(recs))
+	for i, rec := range recs {
+		out[i] = Leak{Type: rec.kind, Stack: rec.stack, Collected: rec.collected}
+	}
+	return out
+}
+
+// CheckLeaks returns an error listing every tracked handle not closed yet, or
+// nil if there is none. It is typically called from TestMain after m.Run.
+func CheckLeaks() error {
+	leaked := Leaks()
+	if len(leaked) == 0 {
+		return nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "zim: %d handle(s) not closed", len(leaked))
+	for _, l := range leaked {
+		state := ""
+		if l.Collected {
+			state = " (collected)"
+		}
+		fmt.Fprintf(&b, "\n\n%s%s created at:\n%s", l.Type, state, l.Stack)
+	}
+	return fmt.Errorf("%s", b.String())
+}
+
+// trackHandle records a new handle of the given type, or returns nil when
+// tracking is disabled
+func trackHandle(kind string) *leakRecord {
+	if !leakTracking.Load() {
+		return nil
+	}
+
+	// Skip runtime.Callers, trackHandle and newHandle
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	var stack strings.Builder
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&stack, "\t%s\n\t\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+
+	leakMu.Lock()
+	defer leakMu.Unlock()
+	leakNext++
+	rec := &leakRecord{id: leakNext, kind: kind, stack: stack.String()}
+	leaks[rec.id] = rec
+	return rec
+}
+
+// untrackHandle forgets a closed handle
+func untrackHandle(rec *leakRecord) {
+	if rec == nil {
+		return
+	}
+	leakMu.Lock()
+	defer leakMu.Unlock()
+	delete(leaks, rec.id)
+}
+
+// collectHandle marks a handle freed by the garbage collector
+func collectHandle(rec *leakRecord) {
+	if rec == nil {
+		return
+	}
+	leakMu.Lock()
+	defer leakMu.Unlock()
+	rec.collected = true
+}
@@ -0,0 +1,123 @@
+package zim
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMain fails the run if a test leaks a handle, when ZIM_LEAK_TRACKING
+// is set
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if code == 0 && leakTracking.Load() {
+		if err := CheckLeaks(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			code = 1
+		}
+	}
+	os.Exit(code)
+}
+
+// withLeakTracking enables tracking for the duration of the test, unless it
+// was already enabled for the whole run
+func withLeakTracking(t *testing.T) {
+	t.Helper()
+	if leakTracking.Load() {
+		return
+	}
+	EnableLeakTracking()
+	t.Cleanup(DisableLeakTracking)
+}
+
+func TestLeakTracking_Close(t *testing.T) {
+	withLeakTracking(t)
+	before := LiveHandles()
+
+	archive, err := NewArchive(getTestZimPath())
+	if err != nil {
+		t.Fatalf("Failed to open valid ZIM archive: %v", err)
+	}
+	entry, err := archive.GetMainEntry()
+	if err != nil {
+		t.Fatalf("Failed to get main entry: %v", err)
+	}
+
+	live := LiveHandles()
+	if live["Archive"] != before["Archive"]+1 || live["Entry"] != before["Entry"]+1 {
+		t.Errorf("Expected one more live Archive and Entry, got %v (before %v)", live, before)
+	}
+
+	entry.Close()
+	archive.Close()
+
+	live = LiveHandles()
+	if live["Archive"] != before["Archive"] || live["Entry"] != before["Entry"] {
+		t.Errorf("Expected closed handles to be untracked, got %v (before %v)", live, before)
+	}
+}
+
+func TestLeakTracking_Report(t *testing.T) {
+	withLeakTracking(t)
+
+	creator := leakCreator(t)
+	err := CheckLeaks()
+	if err == nil {
+		t.Fatal("Expected CheckLeaks to report the open creator")
+	}
+	if !strings.Contains(err.Error(), "leakCreator") {
+		t.Errorf("Expected the leak report to include the creation stack, got:\n%v", err)
+	}
+	creator.Close()
+
+	for _, l := range Leaks() {
+		if strings.Contains(l.Stack, "leakCreator") {
+			t.Errorf("Expected closed creator to be untracked, still reported:\n%s", l.Stack)
+		}
+	}
+}
+
+func TestLeakTracking_Collected(t *testing.T) {
+	withLeakTracking(t)
+
+	leakCreator(t)
+	var found *Leak
+	for range 50 {
+		runtime.GC()
+		for _, l := range Leaks() {
+			if l.Collected && strings.Contains(l.Stack, "leakCreator") {
+				found = &l
+			}
+		}
+		if found != nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if found == nil {
+		t.Fatal("Expected the unreachable creator to be freed and reported as collected")
+	}
+
+	// Forget the deliberate leak so that TestMain does not report it when
+	// tracking is enabled for the whole run
+	leakMu.Lock()
+	for id, rec := range leaks {
+		if rec.collected && strings.Contains(rec.stack, "leakCreator") {
+			delete(leaks, id)
+		}
+	}
+	leakMu.Unlock()
+}
+
+//go:noinline
+func leakCreator(t *testing.T) *Creator {
+	t.Helper()
+	creator, err := NewCreator()
+	if err != nil {
+		t.Fatalf("Failed to create ZIM Creator: %v", err)
+	}
+	return creator
+}
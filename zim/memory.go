@@ -0,0 +1,58 @@
+package zim
+
+import (
+	"io/fs"
+	"os"
+)
+
+// NewArchiveFromBytes opens a ZIM archive held in memory, e.g. one shipped
+// inside the binary with //go:embed. The content is copied into an anonymous
+// memory file (or a temporary file where memfd is unavailable) which is
+// released when the archive is closed. Only libzim keeps a descriptor on it.
+func NewArchiveFromBytes(data []byte) (*Archive, error) {
+	return newArchiveFromBytes("NewArchiveFromBytes", data)
+}
+
+// NewArchiveFromFS opens the ZIM archive stored as name in fsys, such as an
+// embed.FS. See NewArchiveFromBytes.
+func NewArchiveFromFS(fsys fs.FS, name string) (*Archive, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, &Error{Op: "NewArchiveFromFS", Kind: ErrIO, Message: err.Error()}
+	}
+	return newArchiveFromBytes("NewArchiveFromFS", data)
+}
+
+func newArchiveFromBytes(op string, data []byte) (*Archive, error) {
+	f, cleanup, err := newBackingFile(data)
+	if err != nil {
+		return nil, &Error{Op: op, Kind: ErrIO, Message: err.Error()}
+	}
+
+	arch, err := newArchiveFromFile(op, f, cleanup)
+	// libzim reads through its own duplicate of the descriptor
+	f.Close()
+	if err != nil {
+		if cleanup != nil {
+			cleanup()
+		}
+		return nil, err
+	}
+	return arch, nil
+}
+
+// newTempBackingFile writes data to a temporary file, removed by cleanup
+func newTempBackingFile(data []byte) (*os.File, func(), error) {
+	f, err := os.CreateTemp("", "zim-*.zim")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() { os.Remove(f.Name()) }
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		cleanup()
+		return nil, nil, err
+	}
+	return f, cleanup, nil
+}
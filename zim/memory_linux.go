@@ -0,0 +1,38 @@
+//go:build linux
+
+package zim
+
+/*
+#define _GNU_SOURCE
+#include <stdlib.h>
+#include <sys/mman.h>
+
+static int zim_memfd_create(const char* name) {
+    return memfd_create(name, MFD_CLOEXEC);
+}
+*/
+import "C"
+import (
+	"os"
+	"unsafe"
+)
+
+// newBackingFile stores data in an anonymous memfd, falling back to a
+// temporary file on kernels without memfd_create. The memfd needs no cleanup:
+// it is released with the last descriptor on it.
+func newBackingFile(data []byte) (*os.File, func(), error) {
+	cName := C.CString("zim-archive")
+	defer C.free(unsafe.Pointer(cName))
+
+	fd, err := C.zim_memfd_create(cName)
+	if fd < 0 {
+		return newTempBackingFile(data)
+	}
+
+	f := os.NewFile(uintptr(fd), "memfd:zim-archive")
+	if _, err = f.Write(data); err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	return f, nil, nil
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package zim
+
+import "os"
+
+// newBackingFile stores data in a temporary file, removed by cleanup
+func newBackingFile(data []byte) (*os.File, func(), error) {
+	return newTempBackingFile(data)
+}
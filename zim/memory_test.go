@@ -0,0 +1,88 @@
+package zim
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestArchive_FromBytes(t *testing.T) {
+	data, err := os.ReadFile(getTestZimPath())
+	if err != nil {
+		t.Fatalf("Failed to read test ZIM file: %v", err)
+	}
+
+	archive, err := NewArchiveFromBytes(data)
+	if err != nil {
+		t.Fatalf("Failed to open ZIM archive from bytes: %v", err)
+	}
+
+	if archive.GetEntryCount() == 0 {
+		t.Errorf("Expected in-memory archive to have entries")
+	}
+
+	entry, err := archive.GetEntryByIndex(0)
+	if err != nil {
+		t.Fatalf("Failed to get entry at index 0: %v", err)
+	}
+	entry.Close()
+
+	archive.Close()
+	if _, err := archive.GetEntryByIndex(0); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed after Close, got %v", err)
+	}
+}
+
+func TestArchive_FromFS(t *testing.T) {
+	fsys := os.DirFS(filepath.Join("..", "testdata"))
+
+	archive, err := NewArchiveFromFS(fsys, "test.zim")
+	if err != nil {
+		t.Fatalf("Failed to open ZIM archive from fs.FS: %v", err)
+	}
+	defer archive.Close()
+
+	if archive.GetEntryCount() == 0 {
+		t.Errorf("Expected archive to have entries")
+	}
+
+	_, err = NewArchiveFromFS(fsys, "does_not_exist.zim")
+	if !errors.Is(err, ErrIO) {
+		t.Errorf("Expected ErrIO for a missing file, got %v", err)
+	}
+}
+
+func TestArchive_FromBytesInvalid(t *testing.T) {
+	_, err := NewArchiveFromBytes([]byte("definitely not a zim archive"))
+	if !errors.Is(err, ErrInvalidArchive) {
+		t.Errorf("Expected ErrInvalidArchive, got %v", err)
+	}
+}
+
+func TestArchive_FromBytesDescriptors(t *testing.T) {
+	countFds := func() int {
+		fds, err := os.ReadDir("/proc/self/fd")
+		if err != nil {
+			t.Skipf("Skipping descriptor count without /proc: %v", err)
+		}
+		return len(fds)
+	}
+
+	data, err := os.ReadFile(getTestZimPath())
+	if err != nil {
+		t.Fatalf("Failed to read test ZIM file: %v", err)
+	}
+
+	before := countFds()
+	archive, err := NewArchiveFromBytes(data)
+	if err != nil {
+		t.Fatalf("Failed to open ZIM archive from bytes: %v", err)
+	}
+	defer archive.Close()
+
+	// Only the descriptor duplicated by libzim stays open
+	if opened := countFds() - before; opened > 1 {
+		t.Errorf("Expected at most 1 descriptor held by the archive, got %d", opened)
+	}
+}
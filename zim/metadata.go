@@ -0,0 +1,141 @@
+package zim
+
+/*
+#include <stdlib.h>
+#include "zim_wrapper.h"
+*/
+import "C"
+import (
+	"errors"
+	"strings"
+	"unsafe"
+)
+
+// Metadata holds the standard metadata entries of a ZIM archive.
+// Missing entries are left empty.
+type Metadata struct {
+	Name            string
+	Title           string
+	Description     string
+	LongDescription string
+	Language        string
+	Creator         string
+	Publisher       string
+	Date            string
+	Tags            []string
+	Flavour         string
+	Source          string
+	License         string
+	Relation        string
+	Counter         string
+}
+
+// GetMetadata returns the raw value of the metadata entry with the given name
+// (e.g. "Title"). It returns ErrNotFound if the archive has no such entry.
+func (a *Archive) GetMetadata(name string) (string, error) {
+	if !a.h.acquire() {
+		return "", closedError("GetMetadata")
+	}
+	defer a.h.release()
+
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+
+	var cerr C.zim_error_t
+	var size C.uint64_t
+	cValue := C.zim_archive_get_metadata(a.ptr, cName, &size, &cerr)
+	if cValue == nil {
+		return "", newError("GetMetadata", &cerr, ErrNotFound)
+	}
+	defer C.free(unsafe.Pointer(cValue))
+
+	return C.GoStringN(cValue, C.int(size)), nil
+}
+
+// MetadataKeys lists the names of all metadata entries in the archive
+func (a *Archive) MetadataKeys() ([]string, error) {
+	if !a.h.acquire() {
+		return nil, closedError("MetadataKeys")
+	}
+	defer a.h.release()
+
+	var cerr C.zim_error_t
+	var count C.int
+	cKeys := C.zim_archive_get_metadata_keys(a.ptr, &count, &cerr)
+	if cKeys == nil {
+		if cerr.code == C.ZIM_OK {
+			return []string{}, nil
+		}
+		return nil, newError("MetadataKeys", &cerr, nil)
+	}
+	defer C.zim_string_array_free(cKeys, count)
+
+	return goStrings(cKeys, count), nil
+}
+
+// Metadata reads the standard metadata entries of the archive
+func (a *Archive) Metadata() (Metadata, error) {
+	var md Metadata
+	fields := []struct {
+		name string
+		dst  *string
+	}{
+		{"Name", &md.Name},
+		{"Title", &md.Title},
+		{"Description", &md.Description},
+		{"LongDescription", &md.LongDescription},
+		{"Language", &md.Language},
+		{"Creator", &md.Creator},
+		{"Publisher", &md.Publisher},
+		{"Date", &md.Date},
+		{"Flavour", &md.Flavour},
+		{"Source", &md.Source},
+		{"License", &md.License},
+		{"Relation", &md.Relation},
+		{"Counter", &md.Counter},
+	}
+
+	for _, f := range fields {
+		value, err := a.GetMetadata(f.name)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return Metadata{}, err
+		}
+		*f.dst = value
+	}
+
+	tags, err := a.GetMetadata("Tags")
+	switch {
+	case err == nil:
+		md.Tags = splitTags(tags)
+	case !errors.Is(err, ErrNotFound):
+		return Metadata{}, err
+	}
+
+	return md, nil
+}
+
+// splitTags splits the semicolon separated Tags metadata
+func splitTags(tags string) []string {
+	var out []string
+	for _, tag := range strings.Split(tags, ";") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			out = append(out, tag)
+		}
+	}
+	return out
+}
+
+// goStrings copies a C array of count strings into a Go slice
+func goStrings(arr **C.char, count C.int) []string {
+	cStrs := unsafe.Slice(arr, int(count))
+	out := make([]string, len(cStrs))
+	for i, cStr := range cStrs {
+		if cStr != nil {
+			out[i] = C.GoString(cStr)
+		}
+	}
+	return out
+}
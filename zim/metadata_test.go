@@ -0,0 +1,52 @@
+package zim
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestArchive_Metadata(t *testing.T) {
+	archive, err := NewArchive(getTestSearchZimPath())
+	if err != nil {
+		t.Fatalf("Failed to open valid ZIM archive: %v", err)
+	}
+	defer archive.Close()
+
+	keys, err := archive.MetadataKeys()
+	if err != nil {
+		t.Fatalf("Failed to list metadata keys: %v", err)
+	}
+	t.Logf("Metadata keys: %v", keys)
+
+	if !slices.Contains(keys, "Title") {
+		t.Fatalf("Expected metadata keys to contain Title, got %v", keys)
+	}
+
+	title, err := archive.GetMetadata("Title")
+	if err != nil {
+		t.Fatalf("Failed to read Title metadata: %v", err)
+	}
+
+	md, err := archive.Metadata()
+	if err != nil {
+		t.Fatalf("Failed to read metadata: %v", err)
+	}
+	if md.Title != title {
+		t.Errorf("Expected Metadata().Title to be %q, got %q", title, md.Title)
+	}
+	t.Logf("Metadata: %+v", md)
+
+	_, err = archive.GetMetadata("ThisMetadataDoesNotExist")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for missing metadata, got %v", err)
+	}
+}
+
+func TestSplitTags(t *testing.T) {
+	got := splitTags("wikipedia; _category:wikipedia;;_pictures:no ")
+	want := []string{"wikipedia", "_category:wikipedia", "_pictures:no"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,91 @@
+package zim
+
+/*
+#include <stdlib.h>
+#include "zim_wrapper.h"
+*/
+import "C"
+import "unsafe"
+
+// OpenOptions configures how NewArchiveWithOptions opens an archive.
+// Zero values keep the libzim defaults. The cluster cache is shared by every
+// archive of the process and is set with SetClusterCacheMaxSize instead.
+type OpenOptions struct {
+	// PreloadXapianDB opens the fulltext and title indexes while opening the
+	// archive, instead of on the first search. nil keeps the libzim default,
+	// which preloads them.
+	PreloadXapianDB *bool
+	// PreloadDirentRanges is the number of path ranges loaded while opening
+	// the archive to speed up lookups by path
+	PreloadDirentRanges int
+	// VerifyChecksum verifies the archive checksum before returning. This
+	// reads the whole file. Archives without a checksum fail with
+	// ErrNoChecksum.
+	VerifyChecksum bool
+	// DirentCacheSize is the number of directory entries cached by the archive
+	DirentCacheSize int
+	// DirentLookupCacheSize is the number of path ranges kept to speed up
+	// lookups by path
+	DirentLookupCacheSize int
+}
+
+// NewArchiveWithOptions opens a ZIM archive from the given file path and
+// applies opts to it
+func NewArchiveWithOptions(path string, opts OpenOptions) (*Archive, error) {
+	path = splitArchiveBase(path)
+	if err := checkArchiveFile("NewArchiveWithOptions", path); err != nil {
+		return nil, err
+	}
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+
+	preloadXapian := -1
+	if opts.PreloadXapianDB != nil {
+		preloadXapian = 0
+		if *opts.PreloadXapianDB {
+			preloadXapian = 1
+		}
+	}
+
+	preloadRanges := -1
+	if opts.PreloadDirentRanges > 0 {
+		preloadRanges = opts.PreloadDirentRanges
+	}
+
+	var cerr C.zim_error_t
+	ptr := C.zim_archive_new_with_config(cPath, C.int(preloadXapian), C.int(preloadRanges), &cerr)
+	if ptr == nil {
+		return nil, newError("NewArchiveWithOptions", &cerr, ErrInvalidArchive)
+	}
+
+	arch := newArchive(ptr, path, nil)
+
+	if opts.VerifyChecksum {
+		if !arch.HasChecksum() {
+			arch.Close()
+			return nil, &Error{Op: "NewArchiveWithOptions", Kind: ErrNoChecksum}
+		}
+		ok, err := arch.VerifyChecksum()
+		if err == nil && !ok {
+			err = &Error{Op: "NewArchiveWithOptions", Kind: ErrInvalidArchive, Message: "checksum mismatch"}
+		}
+		if err != nil {
+			arch.Close()
+			return nil, err
+		}
+	}
+
+	if opts.DirentCacheSize > 0 {
+		if err := arch.SetDirentCacheMaxSize(opts.DirentCacheSize); err != nil {
+			arch.Close()
+			return nil, err
+		}
+	}
+	if opts.DirentLookupCacheSize > 0 {
+		if err := arch.SetDirentLookupCacheMaxSize(opts.DirentLookupCacheSize); err != nil {
+			arch.Close()
+			return nil, err
+		}
+	}
+	return arch, nil
+}
@@ -0,0 +1,70 @@
+package zim
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestArchive_OpenOptionsCaches(t *testing.T) {
+	archive, err := NewArchiveWithOptions(getTestZimPath(), OpenOptions{
+		DirentCacheSize:       42,
+		DirentLookupCacheSize: 128,
+	})
+	if err != nil {
+		t.Fatalf("Failed to open valid ZIM archive: %v", err)
+	}
+	defer archive.Close()
+
+	if got := archive.DirentCacheMaxSize(); got != 42 {
+		t.Errorf("Expected dirent cache max size 42, got %d", got)
+	}
+	if got := archive.DirentLookupCacheMaxSize(); got != 128 {
+		t.Errorf("Expected dirent lookup cache max size 128, got %d", got)
+	}
+	if got := archive.DirentCacheSize(); got > 42 {
+		t.Errorf("Expected at most 42 cached dirents, got %d", got)
+	}
+}
+
+func TestArchive_OpenOptionsPreload(t *testing.T) {
+	preload := false
+	archive, err := NewArchiveWithOptions(getTestSearchZimPath(), OpenOptions{
+		PreloadXapianDB:     &preload,
+		PreloadDirentRanges: 64,
+	})
+	if err != nil {
+		t.Fatalf("Failed to open valid ZIM archive: %v", err)
+	}
+	defer archive.Close()
+
+	if !archive.HasFulltextIndex() {
+		t.Skip("Skipping preload test because the test ZIM does not contain a fulltext index.")
+	}
+
+	searcher, err := NewSearcher(archive)
+	if err != nil {
+		t.Fatalf("Failed to create Searcher: %v", err)
+	}
+	defer searcher.Close()
+}
+
+func TestArchive_OpenOptionsVerifyChecksum(t *testing.T) {
+	reference, err := NewArchive(getTestZimPath())
+	if err != nil {
+		t.Fatalf("Failed to open valid ZIM archive: %v", err)
+	}
+	hasChecksum := reference.HasChecksum()
+	reference.Close()
+
+	archive, err := NewArchiveWithOptions(getTestZimPath(), OpenOptions{VerifyChecksum: true})
+	if !hasChecksum {
+		if !errors.Is(err, ErrNoChecksum) {
+			t.Errorf("Expected ErrNoChecksum for an archive without checksum, got %v", err)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("Failed to open and verify valid ZIM archive: %v", err)
+	}
+	archive.Close()
+}
@@ -0,0 +1,52 @@
+package zim
+
+/*
+#include "zim_wrapper.h"
+*/
+import "C"
+
+// randomAttempts bounds the number of draws made by GetRandomEntryFiltered
+const randomAttempts = 100
+
+// GetRandomEntry returns a random front article. It returns ErrNotFound if
+// the archive has no front article.
+func (a *Archive) GetRandomEntry() (*Entry, error) {
+	if !a.h.acquire() {
+		return nil, closedError("GetRandomEntry")
+	}
+	defer a.h.release()
+
+	var cerr C.zim_error_t
+	ptr := C.zim_archive_get_random_entry(a.ptr, &cerr)
+	if ptr == nil {
+		return nil, newError("GetRandomEntry", &cerr, ErrNotFound)
+	}
+
+	return a.newEntry(ptr), nil
+}
+
+// GetRandomEntryFiltered returns a random front article whose mimetype (after
+// following redirects) is accepted by match. It gives up with ErrNotFound
+// after a bounded number of draws.
+func (a *Archive) GetRandomEntryFiltered(match func(mimetype string) bool) (*Entry, error) {
+	for range randomAttempts {
+		entry, err := a.GetRandomEntry()
+		if err != nil {
+			return nil, err
+		}
+
+		item, err := entry.GetItem(true)
+		if err != nil {
+			entry.Close()
+			continue
+		}
+		mimetype := item.GetMimetype()
+		item.Close()
+
+		if match(mimetype) {
+			return entry, nil
+		}
+		entry.Close()
+	}
+	return nil, &Error{Op: "GetRandomEntryFiltered", Kind: ErrNotFound, Message: "no random entry matched the filter"}
+}
@@ -0,0 +1,63 @@
+package zim
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestArchive_GetRandomEntry(t *testing.T) {
+	path := createTestArchive(t, "index.html",
+		testItem{path: "index.html", mimetype: "text/html", title: "Home", content: "<html></html>", front: true},
+		testItem{path: "page.html", mimetype: "text/html", title: "Page", content: "<html></html>", front: true},
+		testItem{path: "style.css", mimetype: "text/css", content: "body{}"},
+	)
+
+	archive, err := NewArchive(path)
+	if err != nil {
+		t.Fatalf("Failed to open generated ZIM archive: %v", err)
+	}
+	defer archive.Close()
+
+	for range 20 {
+		entry, err := archive.GetRandomEntry()
+		if err != nil {
+			t.Fatalf("Failed to get random entry: %v", err)
+		}
+		// Only front articles may be picked
+		if entry.Path() == "style.css" {
+			t.Errorf("Expected random entry to be a front article, got %q", entry.Path())
+		}
+		entry.Close()
+	}
+
+	entry, err := archive.GetRandomEntryFiltered(func(mimetype string) bool {
+		return strings.HasPrefix(mimetype, "text/html")
+	})
+	if err != nil {
+		t.Fatalf("Failed to get filtered random entry: %v", err)
+	}
+	entry.Close()
+
+	_, err = archive.GetRandomEntryFiltered(func(mimetype string) bool { return false })
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound when nothing matches, got %v", err)
+	}
+}
+
+func TestArchive_GetRandomEntryEmpty(t *testing.T) {
+	path := createTestArchive(t, "",
+		testItem{path: "style.css", mimetype: "text/css", content: "body{}"},
+	)
+
+	archive, err := NewArchive(path)
+	if err != nil {
+		t.Fatalf("Failed to open generated ZIM archive: %v", err)
+	}
+	defer archive.Close()
+
+	_, err = archive.GetRandomEntry()
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound without front articles, got %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+package zim
+
+/*
+#include <stdlib.h>
+#include "zim_wrapper.h"
+*/
+import "C"
+import (
+	"errors"
+	"io"
+	"unsafe"
+)
+
+// itemChunkSize is the amount of content copied per cgo call when streaming
+const itemChunkSize = 256 * 1024
+
+// ItemReader streams the content of an Item without loading it in memory.
+// It implements io.Reader, io.ReaderAt, io.Seeker and io.WriterTo.
+// An ItemReader keeps its Item alive but is not safe for concurrent use,
+// except for ReadAt.
+type ItemReader struct {
+	item *Item
+	size int64
+	off  int64
+	err  error // Set when the item was closed before the reader was created
+}
+
+// Reader returns a reader over the item content. If the item is closed, the
+// reader methods return ErrClosed.
+func (i *Item) Reader() *ItemReader {
+	if !i.h.acquire() {
+		return &ItemReader{item: i, err: closedError("Reader")}
+	}
+	defer i.h.release()
+
+	return &ItemReader{item: i, size: int64(C.zim_item_get_size(i.ptr))}
+}
+
+// WriteTo streams the item content to w in chunks
+func (i *Item) WriteTo(w io.Writer) (int64, error) {
+	return i.Reader().WriteTo(w)
+}
+
+// DirectAccess reports where the item content is stored when it sits in an
+// uncompressed cluster: the file (or archive part) and the offset of the
+// content in it. ok is false for compressed content, and for archives not
+// opened from a path, whose file libzim only knows as a /dev/fd name that
+// may since have been reused.
+func (i *Item) DirectAccess() (path string, offset int64, ok bool) {
+	if i.archive == nil || i.archive.path == "" {
+		return "", 0, false
+	}
+	if !i.h.acquire() {
+		return "", 0, false
+	}
+	defer i.h.release()
+
+	var cOffset C.uint64_t
+	cPath := C.zim_item_get_direct_access(i.ptr, &cOffset)
+	if cPath == nil {
+		return "", 0, false
+	}
+	defer C.free(unsafe.Pointer(cPath))
+	return C.GoString(cPath), int64(cOffset), true
+}
+
+// Size returns the size of the item content
+func (r *ItemReader) Size() int64 {
+	return r.size
+}
+
+// ReadAt reads len(p) bytes of content starting at off
+func (r *ItemReader) ReadAt(p []byte, off int64) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	if !r.item.h.acquire() {
+		return 0, closedError("ReadAt")
+	}
+	defer r.item.h.release()
+
+	if off < 0 {
+		return 0, errors.New("ItemReader.ReadAt: negative offset")
+	}
+	if off >= r.size {
+		return 0, io.EOF
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	var cerr C.zim_error_t
+	n := int(C.zim_item_read_at(r.item.ptr, C.uint64_t(off), (*C.char)(unsafe.Pointer(&p[0])), C.uint64_t(len(p)), &cerr))
+	if cerr.code != C.ZIM_OK {
+		return n, newError("ReadAt", &cerr, ErrIO)
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// Read reads the next len(p) bytes of content
+func (r *ItemReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	if r.off >= r.size {
+		return 0, io.EOF
+	}
+	if rest := r.size - r.off; int64(len(p)) > rest {
+		p = p[:rest]
+	}
+
+	n, err := r.ReadAt(p, r.off)
+	r.off += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+// Seek sets the offset of the next Read
+func (r *ItemReader) Seek(offset int64, whence int) (int64, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.off
+	case io.SeekEnd:
+		offset += r.size
+	default:
+		return 0, errors.New("ItemReader.Seek: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("ItemReader.Seek: negative position")
+	}
+	r.off = offset
+	return offset, nil
+}
+
+// WriteTo writes the remaining content to w in chunks
+func (r *ItemReader) WriteTo(w io.Writer) (int64, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	buf := make([]byte, min(itemChunkSize, max(r.size-r.off, 0)))
+	var written int64
+	for r.off < r.size {
+		n, err := r.Read(buf)
+		if n > 0 {
+			m, werr := w.Write(buf[:n])
+			written += int64(m)
+			if werr != nil {
+				return written, werr
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
@@ -0,0 +1,153 @@
+package zim
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestItem_Reader(t *testing.T) {
+	content := strings.Repeat("0123456789", 100_000)
+	path := createTestArchive(t, "",
+		testItem{path: "big.txt", mimetype: "text/plain", title: "Big", content: content},
+	)
+
+	archive, err := NewArchive(path)
+	if err != nil {
+		t.Fatalf("Failed to open generated ZIM archive: %v", err)
+	}
+	defer archive.Close()
+
+	entry, err := archive.GetEntryByPath("big.txt")
+	if err != nil {
+		t.Fatalf("Failed to get entry: %v", err)
+	}
+	defer entry.Close()
+
+	item, err := entry.GetItem(true)
+	if err != nil {
+		t.Fatalf("Failed to get item: %v", err)
+	}
+	defer item.Close()
+
+	r := item.Reader()
+	if r.Size() != int64(len(content)) {
+		t.Fatalf("Expected size %d, got %d", len(content), r.Size())
+	}
+
+	all, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("Failed to read item: %v", err)
+	}
+	if string(all) != content {
+		t.Errorf("Content read through Reader does not match")
+	}
+
+	buf := make([]byte, 10)
+	n, err := r.ReadAt(buf, 12345)
+	if err != nil || n != 10 || string(buf) != content[12345:12355] {
+		t.Errorf("ReadAt returned %d %q %v", n, buf[:n], err)
+	}
+
+	n, err = r.ReadAt(buf, int64(len(content))-4)
+	if err != io.EOF || n != 4 {
+		t.Errorf("Expected a short read with io.EOF at the end, got %d %v", n, err)
+	}
+
+	if _, err := r.Seek(-6, io.SeekEnd); err != nil {
+		t.Fatalf("Failed to seek: %v", err)
+	}
+	tail, _ := io.ReadAll(r)
+	if string(tail) != content[len(content)-6:] {
+		t.Errorf("Expected tail %q, got %q", content[len(content)-6:], tail)
+	}
+
+	var out bytes.Buffer
+	written, err := item.WriteTo(&out)
+	if err != nil {
+		t.Fatalf("Failed to stream item: %v", err)
+	}
+	if written != int64(len(content)) || out.String() != content {
+		t.Errorf("Content streamed through WriteTo does not match (%d bytes)", written)
+	}
+}
+
+func TestItem_DirectAccess(t *testing.T) {
+	content := "uncompressed payload"
+	path := createTestArchive(t, "",
+		testItem{path: "raw.bin", mimetype: "application/octet-stream", content: content},
+	)
+
+	archive, err := NewArchive(path)
+	if err != nil {
+		t.Fatalf("Failed to open generated ZIM archive: %v", err)
+	}
+	defer archive.Close()
+
+	entry, err := archive.GetEntryByPath("raw.bin")
+	if err != nil {
+		t.Fatalf("Failed to get entry: %v", err)
+	}
+	defer entry.Close()
+
+	item, err := entry.GetItem(true)
+	if err != nil {
+		t.Fatalf("Failed to get item: %v", err)
+	}
+	defer item.Close()
+
+	file, offset, ok := item.DirectAccess()
+	if !ok {
+		t.Fatalf("Expected content of an uncompressed archive to be directly accessible")
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatalf("Failed to open %s: %v", file, err)
+	}
+	defer f.Close()
+
+	buf := make([]byte, len(content))
+	if _, err := f.ReadAt(buf, offset); err != nil {
+		t.Fatalf("Failed to read at offset %d: %v", offset, err)
+	}
+	if string(buf) != content {
+		t.Errorf("Expected %q at offset %d, got %q", content, offset, buf)
+	}
+}
+
+func TestItem_DirectAccessFromFile(t *testing.T) {
+	path := createTestArchive(t, "",
+		testItem{path: "raw.bin", mimetype: "application/octet-stream", content: "payload"},
+	)
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open %s: %v", path, err)
+	}
+	archive, err := NewArchiveFromFile(f)
+	f.Close()
+	if err != nil {
+		t.Fatalf("Failed to open archive from file: %v", err)
+	}
+	defer archive.Close()
+
+	entry, err := archive.GetEntryByPath("raw.bin")
+	if err != nil {
+		t.Fatalf("Failed to get entry: %v", err)
+	}
+	defer entry.Close()
+
+	item, err := entry.GetItem(true)
+	if err != nil {
+		t.Fatalf("Failed to get item: %v", err)
+	}
+	defer item.Close()
+
+	// libzim only knows the file as /dev/fd/N, which f no longer owns
+	if file, _, ok := item.DirectAccess(); ok {
+		t.Errorf("Expected no direct access for an archive opened from a descriptor, got %s", file)
+	}
+}
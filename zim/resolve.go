@@ -0,0 +1,230 @@
+package zim
+
+/*
+#include <stdlib.h>
+#include "zim_wrapper.h"
+*/
+import "C"
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+	"unsafe"
+)
+
+// legacyNamespaces are the namespaces of archives created before libzim 7
+// that hold user content: articles, images and layout resources
+var legacyNamespaces = []string{"A/", "I/", "-/"}
+
+// ResolveOptions selects the fallbacks tried by ResolvePathWithOptions when
+// no entry has the exact path
+type ResolveOptions struct {
+	// CaseInsensitive matches the last path segment regardless of case. The
+	// lower case and capitalized spellings are looked up first, then the
+	// paths sharing its first letter are scanned, which is slow on large
+	// archives.
+	CaseInsensitive bool
+	// TitleFallback looks the last segment up as a title, with underscores
+	// replaced by spaces
+	TitleFallback bool
+}
+
+// HasNewNamespaceScheme returns true if the archive uses the namespace
+// scheme introduced by libzim 7, where user content paths carry no namespace
+func (a *Archive) HasNewNamespaceScheme() bool {
+	if !a.h.acquire() {
+		return false
+	}
+	defer a.h.release()
+
+	return bool(C.zim_archive_has_new_namespace_scheme(a.ptr))
+}
+
+// ResolvePath finds the entry a link points to. raw may be percent-encoded,
+// in any Unicode normal form, and use either namespace scheme: "A/Foo" is
+// found in a recent archive, "Foo" in a legacy one. It returns ErrNotFound
+// if no entry matches.
+func (a *Archive) ResolvePath(raw string) (*Entry, error) {
+	return a.ResolvePathWithOptions(raw, ResolveOptions{})
+}
+
+// ResolvePathWithOptions is ResolvePath with the fallbacks selected by opts,
+// tried in order once the exact lookups failed
+func (a *Archive) ResolvePathWithOptions(raw string, opts ResolveOptions) (*Entry, error) {
+	newScheme := a.HasNewNamespaceScheme()
+
+	var paths []string
+	for _, p := range decodedPaths(raw) {
+		paths = appendUnique(paths, namespacedPaths(p, newScheme)...)
+	}
+
+	var variants []string
+	if opts.CaseInsensitive {
+		for _, p := range paths {
+			variants = appendUnique(variants, caseVariants(p)...)
+		}
+	}
+
+	for _, p := range append(paths, variants...) {
+		entry, err := a.GetEntryByPath(p)
+		if err == nil {
+			return entry, nil
+		}
+		if !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+	}
+
+	if opts.CaseInsensitive {
+		for _, p := range paths {
+			entry, err := a.findFoldedPath(p)
+			if entry != nil || err != nil {
+				return entry, err
+			}
+		}
+	}
+
+	if opts.TitleFallback {
+		for _, p := range paths {
+			title := strings.ReplaceAll(p[strings.LastIndex(p, "/")+1:], "_", " ")
+			if title == "" {
+				continue
+			}
+			entry, err := a.GetEntryByTitle(title)
+			if err == nil {
+				return entry, nil
+			}
+			if !errors.Is(err, ErrNotFound) {
+				return nil, err
+			}
+		}
+	}
+
+	return nil, &Error{Op: "ResolvePath", Kind: ErrNotFound, Message: "no entry matches " + raw}
+}
+
+// decodedPaths lists the forms raw may take in the archive: as is and NFC
+// normalized first, so that paths holding a literal "%" are found, then
+// percent-decoded
+func decodedPaths(raw string) []string {
+	raw = strings.TrimPrefix(raw, "/")
+
+	paths := appendUnique(nil, raw, normalizeNFC(raw))
+	if decoded, err := url.PathUnescape(raw); err == nil {
+		paths = appendUnique(paths, decoded, normalizeNFC(decoded))
+	}
+	return paths
+}
+
+// namespacedPaths maps path to the scheme of the archive: legacy namespaces
+// are stripped for recent archives and tried in turn for legacy ones
+func namespacedPaths(path string, newScheme bool) []string {
+	if newScheme {
+		for _, ns := range append(legacyNamespaces, "C/") {
+			if rest, ok := strings.CutPrefix(path, ns); ok {
+				return []string{rest, path}
+			}
+		}
+		return []string{path}
+	}
+
+	if len(path) > 2 && path[1] == '/' {
+		return []string{path}
+	}
+	paths := make([]string, 0, len(legacyNamespaces)+1)
+	for _, ns := range legacyNamespaces {
+		paths = append(paths, ns+path)
+	}
+	return append(paths, path)
+}
+
+// caseVariants returns path with its last segment in lower case, then
+// capitalized. libzim lookups are case sensitive, so only these common
+// spellings are tried rather than scanning every entry.
+func caseVariants(path string) []string {
+	dir, name := "", path
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		dir, name = path[:i+1], path[i+1:]
+	}
+
+	variants := []string{dir + strings.ToLower(name)}
+	if r, size := utf8.DecodeRuneInString(name); r != utf8.RuneError {
+		variants = append(variants, dir+string(unicode.ToUpper(r))+strings.ToLower(name[size:]))
+		variants = append(variants, dir+string(unicode.ToUpper(r))+name[size:])
+	}
+	return variants
+}
+
+// findFoldedPath scans the paths starting with the directory of path and the
+// first letter of its last segment, in any case, for one equal to path under
+// Unicode case folding. It returns a nil entry if none matches.
+func (a *Archive) findFoldedPath(path string) (*Entry, error) {
+	dir, name := "", path
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		dir, name = path[:i+1], path[i+1:]
+	}
+	r, _ := utf8.DecodeRuneInString(name)
+	if r == utf8.RuneError {
+		return nil, nil
+	}
+
+	var heads []string
+	for _, c := range []rune{unicode.ToLower(r), unicode.ToUpper(r), unicode.ToTitle(r)} {
+		heads = appendUnique(heads, dir+string(c))
+	}
+	for _, head := range heads {
+		for entry, err := range a.FindByPathPrefix(head) {
+			if err != nil {
+				return nil, err
+			}
+			if strings.EqualFold(entry.Path(), path) {
+				return entry, nil
+			}
+			entry.Close()
+		}
+	}
+	return nil, nil
+}
+
+// normalizeNFC returns s in Unicode normalization form C, the form used by
+// zimwriters for paths and titles
+func normalizeNFC(s string) string {
+	if isASCII(s) {
+		return s
+	}
+
+	cStr := C.CString(s)
+	defer C.free(unsafe.Pointer(cStr))
+
+	cNorm := C.zim_string_normalize(cStr)
+	if cNorm == nil {
+		return s
+	}
+	defer C.free(unsafe.Pointer(cNorm))
+	return C.GoString(cNorm)
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// appendUnique appends the values of add missing from list
+func appendUnique(list []string, add ...string) []string {
+	for _, s := range add {
+		found := false
+		for _, t := range list {
+			found = found || s == t
+		}
+		if !found {
+			list = append(list, s)
+		}
+	}
+	return list
+}
@@ -0,0 +1,87 @@
+package zim
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestArchive_ResolvePath(t *testing.T) {
+	path := createTestArchive(t, "index.html",
+		testItem{path: "index.html", mimetype: "text/html", title: "Home", content: "<p>home</p>", front: true},
+		testItem{path: "café.html", mimetype: "text/html", title: "Café", content: "<p>cafe</p>", front: true},
+		testItem{path: "wiki/Paris", mimetype: "text/html", title: "City of Paris", content: "<p>paris</p>", front: true},
+		testItem{path: "img/logo.png", mimetype: "image/png", title: "", content: "png"},
+		testItem{path: "wiki/Foo_Bar", mimetype: "text/html", title: "Foobar", content: "<p>foo</p>"},
+		testItem{path: "100%.html", mimetype: "text/html", title: "Percent", content: "<p>percent</p>"},
+		testItem{path: "100%25.html", mimetype: "text/html", title: "Escaped percent", content: "<p>escaped</p>"},
+	)
+	archive, err := NewArchive(path)
+	if err != nil {
+		t.Fatalf("Failed to open archive: %v", err)
+	}
+	defer archive.Close()
+
+	if !archive.HasNewNamespaceScheme() {
+		t.Fatal("Expected a freshly created archive to use the new namespace scheme")
+	}
+
+	fallbacks := ResolveOptions{CaseInsensitive: true, TitleFallback: true}
+	cases := []struct {
+		raw  string
+		opts ResolveOptions
+		want string
+	}{
+		{"index.html", ResolveOptions{}, "index.html"},
+		{"/index.html", ResolveOptions{}, "index.html"},
+		{"A/index.html", ResolveOptions{}, "index.html"},
+		{"I/img/logo.png", ResolveOptions{}, "img/logo.png"},
+		{"caf%C3%A9.html", ResolveOptions{}, "café.html"},
+		{"cafe\u0301.html", ResolveOptions{}, "café.html"},
+		// Literal "%" paths win over their decoded form
+		{"100%.html", ResolveOptions{}, "100%.html"},
+		{"100%25.html", ResolveOptions{}, "100%25.html"},
+		{"100%2525.html", ResolveOptions{}, "100%25.html"},
+		{"wiki/paris", fallbacks, "wiki/Paris"},
+		{"wiki/City_of_Paris", fallbacks, "wiki/Paris"},
+		{"wiki/FOO_BAR", fallbacks, "wiki/Foo_Bar"},
+		{"wiki/fOO_bAR", fallbacks, "wiki/Foo_Bar"},
+	}
+	for _, c := range cases {
+		entry, err := archive.ResolvePathWithOptions(c.raw, c.opts)
+		if err != nil {
+			t.Errorf("ResolvePath(%q): %v", c.raw, err)
+			continue
+		}
+		if got := entry.Path(); got != c.want {
+			t.Errorf("ResolvePath(%q): expected %q, got %q", c.raw, c.want, got)
+		}
+		entry.Close()
+	}
+
+	if _, err := archive.ResolvePath("wiki/paris"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound without fallbacks, got %v", err)
+	}
+	if _, err := archive.ResolvePathWithOptions("missing.html", fallbacks); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for missing path, got %v", err)
+	}
+}
+
+func TestNamespacedPaths(t *testing.T) {
+	if got := namespacedPaths("A/Foo", true); !slices.Equal(got, []string{"Foo", "A/Foo"}) {
+		t.Errorf("New scheme: unexpected candidates %v", got)
+	}
+	if got := namespacedPaths("Foo", false); !slices.Equal(got, []string{"A/Foo", "I/Foo", "-/Foo", "Foo"}) {
+		t.Errorf("Legacy scheme: unexpected candidates %v", got)
+	}
+	if got := namespacedPaths("I/logo.png", false); !slices.Equal(got, []string{"I/logo.png"}) {
+		t.Errorf("Legacy scheme with namespace: unexpected candidates %v", got)
+	}
+}
+
+func TestCaseVariants(t *testing.T) {
+	want := []string{"wiki/éclair", "wiki/Éclair", "wiki/ÉCLAIR"}
+	if got := caseVariants("wiki/éCLAIR"); !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
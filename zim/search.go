@@ -6,18 +6,28 @@ package zim
 */
 import "C"
 import (
-	"errors"
-	"runtime"
+	"iter"
+	"slices"
 	"unsafe"
 )
 
+// resultPageSize is the number of search results fetched from libzim at a
+// time by the result iterators
+const resultPageSize = 50
+
 // HasFulltextIndex returns true if the archive has a built-in search index
 func (a *Archive) HasFulltextIndex() bool {
+	if !a.h.acquire() {
+		return false
+	}
+	defer a.h.release()
+
 	return bool(C.zim_archive_has_fulltext_index(a.ptr))
 }
 
 // Query represents a search query string
 type Query struct {
+	h   *handle
 	ptr C.zim_query_t
 }
 
@@ -25,70 +35,179 @@ func NewQuery(queryStr string) (*Query, error) {
 	cQuery := C.CString(queryStr)
 	defer C.free(unsafe.Pointer(cQuery))
 
-	ptr := C.zim_query_new(cQuery)
+	var cerr C.zim_error_t
+	ptr := C.zim_query_new(cQuery, &cerr)
 	if ptr == nil {
-		return nil, errors.New("failed to create search query")
+		return nil, newError("NewQuery", &cerr, nil)
 	}
 
 	q := &Query{ptr: ptr}
-	runtime.SetFinalizer(q, (*Query).Close)
+	q.h = newHandle("Query", func() { C.zim_query_free(ptr) })
+	track(q, q.h)
 	return q, nil
 }
 
-func (q *Query) Close() {
-	if q.ptr != nil {
-		C.zim_query_free(q.ptr)
-		q.ptr = nil
+// SetGeoRange restricts the query to the articles located within meters of
+// the given latitude and longitude, in degrees. It only matches archives
+// whose fulltext index records article positions (e.g. Wikivoyage). The query
+// string may be empty to list every article in the range.
+func (q *Query) SetGeoRange(lat, lon, meters float64) error {
+	switch {
+	case lat < -90 || lat > 90:
+		return &Error{Op: "SetGeoRange", Message: "latitude out of range"}
+	case lon < -180 || lon > 180:
+		return &Error{Op: "SetGeoRange", Message: "longitude out of range"}
+	case meters <= 0:
+		return &Error{Op: "SetGeoRange", Message: "distance must be positive"}
+	}
+
+	if !q.h.acquire() {
+		return closedError("SetGeoRange")
 	}
+	defer q.h.release()
+
+	var cerr C.zim_error_t
+	if !bool(C.zim_query_set_georange(q.ptr, C.float(lat), C.float(lon), C.float(meters), &cerr)) {
+		return newError("SetGeoRange", &cerr, nil)
+	}
+	return nil
+}
+
+func (q *Query) Close() {
+	q.h.close()
 }
 
 // Searcher performs fulltext search over a ZIM Archive
 type Searcher struct {
-	ptr C.zim_searcher_t
+	h        *handle
+	ptr      C.zim_searcher_t
+	archives []*Archive // Keeps the searched archives alive
 }
 
 func NewSearcher(archive *Archive) (*Searcher, error) {
-	ptr := C.zim_searcher_new(archive.ptr)
+	if !archive.h.acquire() {
+		return nil, closedError("NewSearcher")
+	}
+	defer archive.h.release()
+
+	var cerr C.zim_error_t
+	ptr := C.zim_searcher_new(archive.ptr, &cerr)
 	if ptr == nil {
-		return nil, errors.New("failed to initialize searcher (does the archive have a fulltext index?)")
+		return nil, newError("NewSearcher", &cerr, nil)
 	}
 
-	s := &Searcher{ptr: ptr}
-	runtime.SetFinalizer(s, (*Searcher).Close)
+	s := &Searcher{ptr: ptr, archives: []*Archive{archive}}
+	s.h = newHandle("Searcher", func() { C.zim_searcher_free(ptr) }, archive.h)
+	track(s, s.h)
 	return s, nil
 }
 
-func (s *Searcher) Close() {
-	if s.ptr != nil {
-		C.zim_searcher_free(s.ptr)
-		s.ptr = nil
+// NewMultiSearcher creates a Searcher over several archives, returning a
+// single ranked result list. Each result reports the archive it comes from.
+// Every archive must have a fulltext index.
+func NewMultiSearcher(archives ...*Archive) (*Searcher, error) {
+	if len(archives) == 0 {
+		return nil, &Error{Op: "NewMultiSearcher", Message: "no archive given"}
+	}
+
+	handles := make([]*handle, len(archives))
+	ptrs := make([]C.zim_archive_t, len(archives))
+	for i, archive := range archives {
+		handles[i] = archive.h
+		ptrs[i] = archive.ptr
+	}
+	release := acquireAll(handles...)
+	if release == nil {
+		return nil, closedError("NewMultiSearcher")
+	}
+	defer release()
+
+	var cerr C.zim_error_t
+	ptr := C.zim_searcher_new_multi(&ptrs[0], C.int(len(ptrs)), &cerr)
+	if ptr == nil {
+		return nil, newError("NewMultiSearcher", &cerr, nil)
+	}
+
+	s := &Searcher{ptr: ptr, archives: slices.Clone(archives)}
+	s.h = newHandle("Searcher", func() { C.zim_searcher_free(ptr) }, handles...)
+	track(s, s.h)
+	return s, nil
+}
+
+// AddArchive adds an archive to the searched ones. Its results get the next
+// ArchiveIndex. It waits for the searches in progress to return.
+func (s *Searcher) AddArchive(archive *Archive) error {
+	if !s.h.lock() {
+		return closedError("AddArchive")
+	}
+	defer s.h.unlock()
+	if !archive.h.acquire() {
+		return closedError("AddArchive")
+	}
+	defer archive.h.release()
+
+	var cerr C.zim_error_t
+	if !bool(C.zim_searcher_add_archive(s.ptr, archive.ptr, &cerr)) {
+		return newError("AddArchive", &cerr, nil)
 	}
+	s.h.insertParent(archive.h)
+	s.archives = append(s.archives, archive)
+	return nil
+}
+
+// Archives lists the searched archives, in the order of ArchiveIndex
+func (s *Searcher) Archives() []*Archive {
+	if !s.h.acquire() {
+		return nil
+	}
+	defer s.h.release()
+
+	return slices.Clone(s.archives)
+}
+
+func (s *Searcher) Close() {
+	s.h.close()
 }
 
 // Search executes a query and holds the results
 type Search struct {
-	ptr C.zim_search_t
+	h        *handle
+	ptr      C.zim_search_t
+	searcher *Searcher // Keeps the searcher alive while results are read
 }
 
 func (s *Searcher) Search(query *Query) (*Search, error) {
-	ptr := C.zim_searcher_search(s.ptr, query.ptr)
+	if !s.h.acquire() {
+		return nil, closedError("Search")
+	}
+	defer s.h.release()
+	if !query.h.acquire() {
+		return nil, closedError("Search")
+	}
+	defer query.h.release()
+
+	var cerr C.zim_error_t
+	ptr := C.zim_searcher_search(s.ptr, query.ptr, &cerr)
 	if ptr == nil {
-		return nil, errors.New("search execution failed")
+		return nil, newError("Search", &cerr, nil)
 	}
 
-	search := &Search{ptr: ptr}
-	runtime.SetFinalizer(search, (*Search).Close)
+	search := &Search{ptr: ptr, searcher: s}
+	search.h = newHandle("Search", func() { C.zim_search_free(ptr) }, s.h)
+	track(search, search.h)
 	return search, nil
 }
 
 func (s *Search) Close() {
-	if s.ptr != nil {
-		C.zim_search_free(s.ptr)
-		s.ptr = nil
-	}
+	s.h.close()
 }
 
 func (s *Search) GetEstimatedMatches() int {
+	if !s.h.acquire() {
+		return 0
+	}
+	defer s.h.release()
+
 	return int(C.zim_search_get_estimated_matches(s.ptr))
 }
 
@@ -99,13 +218,35 @@ type SearchResult struct {
 	Snippet   string
 	Score     int
 	WordCount int
+	// ArchiveIndex is the position, in Searcher.Archives, of the archive
+	// holding the entry
+	ArchiveIndex int
+	// ArchiveUUID is the UUID of the archive holding the entry
+	ArchiveUUID string
+	Mimetype    string // Of the item, redirects followed
+	Size        uint64 // Of the item content
+
+	archive *Archive
+	index   uint32
+}
+
+// Entry returns the entry behind the result, without looking its path up.
+// It returns ErrNotFound if the entry could not be read with the results.
+func (r SearchResult) Entry() (*Entry, error) {
+	return resultEntry("Entry", r.archive, r.index)
 }
 
 // GetResults fetches a slice of results, handling the C++ iterator safely in the background
 func (s *Search) GetResults(start, maxResults int) ([]SearchResult, error) {
-	setPtr := C.zim_search_get_results(s.ptr, C.int(start), C.int(maxResults))
+	if !s.h.acquire() {
+		return nil, closedError("GetResults")
+	}
+	defer s.h.release()
+
+	var cerr C.zim_error_t
+	setPtr := C.zim_search_get_results(s.ptr, C.int(start), C.int(maxResults), &cerr)
 	if setPtr == nil {
-		return nil, errors.New("failed to retrieve search results")
+		return nil, newError("GetResults", &cerr, nil)
 	}
 	defer C.zim_search_result_set_free(setPtr)
 
@@ -122,7 +263,11 @@ func (s *Search) GetResults(start, maxResults int) ([]SearchResult, error) {
 		cTitle := C.zim_search_iterator_get_title(beginIt)
 		cSnippet := C.zim_search_iterator_get_snippet(beginIt)
 
-		var path, title, snippet string
+		cZimID := C.zim_search_iterator_get_zim_id(beginIt)
+		var cEntry C.zim_result_entry_t
+		hasEntry := bool(C.zim_search_iterator_get_entry(beginIt, &cEntry))
+
+		var path, title, snippet, zimID string
 		if cPath != nil {
 			path = C.GoString(cPath)
 			C.free(unsafe.Pointer(cPath))
@@ -136,12 +281,26 @@ func (s *Search) GetResults(start, maxResults int) ([]SearchResult, error) {
 			C.free(unsafe.Pointer(cSnippet))
 		}
 
+		if cZimID != nil {
+			zimID = C.GoString(cZimID)
+			C.free(unsafe.Pointer(cZimID))
+		}
+
 		res := SearchResult{
-			Path:      path,
-			Title:     title,
-			Snippet:   snippet,
-			Score:     int(C.zim_search_iterator_get_score(beginIt)),
-			WordCount: int(C.zim_search_iterator_get_word_count(beginIt)),
+			Path:         path,
+			Title:        title,
+			Snippet:      snippet,
+			Score:        int(C.zim_search_iterator_get_score(beginIt)),
+			WordCount:    int(C.zim_search_iterator_get_word_count(beginIt)),
+			ArchiveIndex: int(C.zim_search_iterator_get_file_index(beginIt)),
+			ArchiveUUID:  zimID,
+		}
+		if hasEntry {
+			res.Mimetype, res.Size = resultEntryItem(&cEntry)
+			if res.ArchiveIndex >= 0 && res.ArchiveIndex < len(s.searcher.archives) {
+				res.archive = s.searcher.archives[res.ArchiveIndex]
+				res.index = uint32(cEntry.index)
+			}
 		}
 
 		results = append(results, res)
@@ -151,77 +310,128 @@ func (s *Search) GetResults(start, maxResults int) ([]SearchResult, error) {
 	return results, nil
 }
 
+// All iterates over every result of the search, best first. Results are
+// fetched from libzim page by page as the iteration progresses, so breaking
+// early skips the remaining pages. Iteration stops after the first error.
+func (s *Search) All() iter.Seq2[SearchResult, error] {
+	return s.Range(0)
+}
+
+// Range is like All but starts at the result of rank start
+func (s *Search) Range(start int) iter.Seq2[SearchResult, error] {
+	return paginate("Range", start, s.GetResults)
+}
+
 // --- Suggestion API ---
 
 // SuggestionSearcher provides suggestion search over titles in a ZIM Archive
 type SuggestionSearcher struct {
-	ptr C.zim_suggestion_searcher_t
+	h       *handle
+	ptr     C.zim_suggestion_searcher_t
+	archive *Archive // Keeps the archive alive
 }
 
 func NewSuggestionSearcher(archive *Archive) (*SuggestionSearcher, error) {
-	ptr := C.zim_suggestion_searcher_new(archive.ptr)
+	if !archive.h.acquire() {
+		return nil, closedError("NewSuggestionSearcher")
+	}
+	defer archive.h.release()
+
+	var cerr C.zim_error_t
+	ptr := C.zim_suggestion_searcher_new(archive.ptr, &cerr)
 	if ptr == nil {
-		return nil, errors.New("failed to create suggestion searcher")
+		return nil, newError("NewSuggestionSearcher", &cerr, nil)
 	}
 
-	s := &SuggestionSearcher{ptr: ptr}
-	runtime.SetFinalizer(s, (*SuggestionSearcher).Close)
+	s := &SuggestionSearcher{ptr: ptr, archive: archive}
+	s.h = newHandle("SuggestionSearcher", func() { C.zim_suggestion_searcher_free(ptr) }, archive.h)
+	track(s, s.h)
 	return s, nil
 }
 
 func (s *SuggestionSearcher) Close() {
-	if s.ptr != nil {
-		C.zim_suggestion_searcher_free(s.ptr)
-		s.ptr = nil
-	}
+	s.h.close()
 }
 
 func (s *SuggestionSearcher) SetVerbose(verbose bool) {
+	if !s.h.acquire() {
+		return
+	}
+	defer s.h.release()
+
 	C.zim_suggestion_searcher_set_verbose(s.ptr, C.bool(verbose))
 }
 
 // SuggestionSearch represents a suggestion search query
 type SuggestionSearch struct {
-	ptr C.zim_suggestion_search_t
+	h        *handle
+	ptr      C.zim_suggestion_search_t
+	searcher *SuggestionSearcher // Keeps the searcher alive while results are read
 }
 
 func (s *SuggestionSearcher) Suggest(query string) (*SuggestionSearch, error) {
+	if !s.h.acquire() {
+		return nil, closedError("Suggest")
+	}
+	defer s.h.release()
+
 	cQuery := C.CString(query)
 	defer C.free(unsafe.Pointer(cQuery))
 
-	ptr := C.zim_suggestion_searcher_suggest(s.ptr, cQuery)
+	var cerr C.zim_error_t
+	ptr := C.zim_suggestion_searcher_suggest(s.ptr, cQuery, &cerr)
 	if ptr == nil {
-		return nil, errors.New("suggestion search failed")
+		return nil, newError("Suggest", &cerr, nil)
 	}
 
-	search := &SuggestionSearch{ptr: ptr}
-	runtime.SetFinalizer(search, (*SuggestionSearch).Close)
+	search := &SuggestionSearch{ptr: ptr, searcher: s}
+	search.h = newHandle("SuggestionSearch", func() { C.zim_suggestion_search_free(ptr) }, s.h)
+	track(search, search.h)
 	return search, nil
 }
 
 func (s *SuggestionSearch) Close() {
-	if s.ptr != nil {
-		C.zim_suggestion_search_free(s.ptr)
-		s.ptr = nil
-	}
+	s.h.close()
 }
 
 func (s *SuggestionSearch) GetEstimatedMatches() int {
+	if !s.h.acquire() {
+		return 0
+	}
+	defer s.h.release()
+
 	return int(C.zim_suggestion_search_get_estimated_matches(s.ptr))
 }
 
 // SuggestionResult holds metadata for a single suggestion
 type SuggestionResult struct {
-	Path    string `json:"path"`
-	Title   string `json:"title"`
-	Snippet string `json:"snippet"`
+	Path     string `json:"path"`
+	Title    string `json:"title"`
+	Snippet  string `json:"snippet"`
+	Mimetype string `json:"mimetype"` // Of the item, redirects followed
+	Size     uint64 `json:"size"`     // Of the item content
+
+	archive *Archive
+	index   uint32
+}
+
+// Entry returns the entry behind the suggestion, without looking its path up.
+// It returns ErrNotFound if the entry could not be read with the results.
+func (r SuggestionResult) Entry() (*Entry, error) {
+	return resultEntry("Entry", r.archive, r.index)
 }
 
 // GetResults fetches a slice of suggestion results
 func (s *SuggestionSearch) GetResults(start, maxResults int) ([]SuggestionResult, error) {
-	setPtr := C.zim_suggestion_search_get_results(s.ptr, C.int(start), C.int(maxResults))
+	if !s.h.acquire() {
+		return nil, closedError("GetResults")
+	}
+	defer s.h.release()
+
+	var cerr C.zim_error_t
+	setPtr := C.zim_suggestion_search_get_results(s.ptr, C.int(start), C.int(maxResults), &cerr)
 	if setPtr == nil {
-		return nil, errors.New("failed to retrieve suggestion results")
+		return nil, newError("GetResults", &cerr, nil)
 	}
 	defer C.zim_suggestion_result_set_free(setPtr)
 
@@ -251,14 +461,80 @@ func (s *SuggestionSearch) GetResults(start, maxResults int) ([]SuggestionResult
 			C.free(unsafe.Pointer(cSnippet))
 		}
 
-		results = append(results, SuggestionResult{
+		res := SuggestionResult{
 			Path:    path,
 			Title:   title,
 			Snippet: snippet,
-		})
+		}
+		var cEntry C.zim_result_entry_t
+		if bool(C.zim_suggestion_iterator_get_entry(beginIt, &cEntry)) {
+			res.Mimetype, res.Size = resultEntryItem(&cEntry)
+			res.archive = s.searcher.archive
+			res.index = uint32(cEntry.index)
+		}
+
+		results = append(results, res)
 
 		C.zim_suggestion_iterator_next(beginIt)
 	}
 
 	return results, nil
 }
+
+// All iterates over every suggestion, best first, fetching them page by page
+// as the iteration progresses. Iteration stops after the first error.
+func (s *SuggestionSearch) All() iter.Seq2[SuggestionResult, error] {
+	return s.Range(0)
+}
+
+// Range is like All but starts at the suggestion of rank start
+func (s *SuggestionSearch) Range(start int) iter.Seq2[SuggestionResult, error] {
+	return paginate("Range", start, s.GetResults)
+}
+
+// resultEntry reads the entry of a result from its archive
+func resultEntry(op string, archive *Archive, index uint32) (*Entry, error) {
+	if archive == nil {
+		return nil, &Error{Op: op, Kind: ErrNotFound, Message: "result has no entry"}
+	}
+	return archive.GetEntryByIndex(index)
+}
+
+// resultEntryItem converts the item details of a result and frees them
+func resultEntryItem(cEntry *C.zim_result_entry_t) (mimetype string, size uint64) {
+	if cEntry.mimetype != nil {
+		mimetype = C.GoString(cEntry.mimetype)
+		C.free(unsafe.Pointer(cEntry.mimetype))
+		cEntry.mimetype = nil
+	}
+	return mimetype, uint64(cEntry.size)
+}
+
+// paginate yields the results returned by fetch, one page at a time, until
+// a page comes back short
+func paginate[T any](op string, start int, fetch func(start, maxResults int) ([]T, error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		if start < 0 {
+			yield(zero, &Error{Op: op, Message: "negative start"})
+			return
+		}
+
+		for {
+			page, err := fetch(start, resultPageSize)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			for _, res := range page {
+				if !yield(res, nil) {
+					return
+				}
+			}
+			if len(page) < resultPageSize {
+				return
+			}
+			start += len(page)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package zim
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -124,3 +125,303 @@ func TestSuggestionAPI(t *testing.T) {
 		t.Logf("Result %d: [%s] %s (Snippet: %q)", i, res.Path, res.Title, res.Snippet)
 	}
 }
+
+// TestQuery_SetGeoRange only covers argument validation: none of the test
+// archives record article positions, and the Creator cannot write them, so
+// geo matching itself is left untested.
+func TestQuery_SetGeoRange(t *testing.T) {
+	query, err := NewQuery("")
+	if err != nil {
+		t.Fatalf("Failed to create Query: %v", err)
+	}
+
+	invalid := [][3]float64{{91, 0, 1000}, {-91, 0, 1000}, {0, -181, 1000}, {0, 181, 1000}, {0, 0, 0}}
+	for _, r := range invalid {
+		if err := query.SetGeoRange(r[0], r[1], r[2]); err == nil {
+			t.Errorf("Expected SetGeoRange(%v, %v, %v) to fail", r[0], r[1], r[2])
+		}
+	}
+
+	if err := query.SetGeoRange(48.8566, 2.3522, 5000); err != nil {
+		t.Fatalf("Failed to set geo range: %v", err)
+	}
+
+	query.Close()
+	if err := query.SetGeoRange(48.8566, 2.3522, 5000); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed from closed query, got %v", err)
+	}
+}
+
+func TestMultiSearcher(t *testing.T) {
+	first, err := NewArchive(createIndexedTestArchive(t,
+		testItem{path: "alpha.html", mimetype: "text/html", title: "Alpha", content: "<p>zebra alpha</p>", front: true},
+	))
+	if err != nil {
+		t.Fatalf("Failed to open generated ZIM archive: %v", err)
+	}
+	defer first.Close()
+	second, err := NewArchive(createIndexedTestArchive(t,
+		testItem{path: "beta.html", mimetype: "text/html", title: "Beta", content: "<p>zebra beta</p>", front: true},
+	))
+	if err != nil {
+		t.Fatalf("Failed to open generated ZIM archive: %v", err)
+	}
+	defer second.Close()
+
+	if !first.HasFulltextIndex() || !second.HasFulltextIndex() {
+		t.Skip("Skipping multi-archive search because libzim was built without fulltext indexing.")
+	}
+	if first.UUID() == second.UUID() {
+		t.Fatal("Expected the generated archives to have different UUIDs")
+	}
+
+	if _, err := NewMultiSearcher(); err == nil {
+		t.Error("Expected NewMultiSearcher without archives to fail")
+	}
+
+	searcher, err := NewMultiSearcher(first)
+	if err != nil {
+		t.Fatalf("Failed to create multi-archive Searcher: %v", err)
+	}
+	defer searcher.Close()
+
+	if err := searcher.AddArchive(second); err != nil {
+		t.Fatalf("Failed to add archive: %v", err)
+	}
+	archives := searcher.Archives()
+	if len(archives) != 2 || archives[0] != first || archives[1] != second {
+		t.Fatalf("Expected the searcher to list both archives in order, got %v", archives)
+	}
+
+	query, err := NewQuery("zebra")
+	if err != nil {
+		t.Fatalf("Failed to create Query: %v", err)
+	}
+	defer query.Close()
+
+	search, err := searcher.Search(query)
+	if err != nil {
+		t.Fatalf("Failed to execute Search: %v", err)
+	}
+	defer search.Close()
+
+	results, err := search.GetResults(0, 20)
+	if err != nil {
+		t.Fatalf("Failed to retrieve results: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected one result per archive, got %d", len(results))
+	}
+
+	// Each path only exists in one archive, so the index and UUID of its
+	// result must point to that archive
+	wantIndex := map[string]int{"alpha.html": 0, "beta.html": 1}
+	for _, res := range results {
+		want, ok := wantIndex[res.Path]
+		if !ok {
+			t.Errorf("Unexpected result %s", res.Path)
+			continue
+		}
+		if res.ArchiveIndex != want {
+			t.Errorf("Result %s: expected archive index %d, got %d", res.Path, want, res.ArchiveIndex)
+		}
+		if res.ArchiveUUID != archives[want].UUID() {
+			t.Errorf("Result %s: expected archive UUID %s, got %s", res.Path, archives[want].UUID(), res.ArchiveUUID)
+		}
+		delete(wantIndex, res.Path)
+	}
+}
+
+func TestSearch_All(t *testing.T) {
+	archive, err := NewArchive(getTestSearchZimPath())
+	if err != nil {
+		t.Fatalf("Failed to open valid ZIM archive: %v", err)
+	}
+	defer archive.Close()
+
+	if !archive.HasFulltextIndex() {
+		t.Skip("Skipping result iterator test because the test ZIM does not contain a fulltext index.")
+	}
+
+	searcher, err := NewSearcher(archive)
+	if err != nil {
+		t.Fatalf("Failed to create Searcher: %v", err)
+	}
+	defer searcher.Close()
+
+	query, err := NewQuery("markdown")
+	if err != nil {
+		t.Fatalf("Failed to create Query: %v", err)
+	}
+	defer query.Close()
+
+	search, err := searcher.Search(query)
+	if err != nil {
+		t.Fatalf("Failed to execute Search: %v", err)
+	}
+	defer search.Close()
+
+	want, err := search.GetResults(0, 3*resultPageSize)
+	if err != nil {
+		t.Fatalf("Failed to retrieve results: %v", err)
+	}
+
+	var got []SearchResult
+	for res, err := range search.All() {
+		if err != nil {
+			t.Fatalf("Iteration failed: %v", err)
+		}
+		got = append(got, res)
+		if len(got) == len(want) {
+			break
+		}
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d results, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i].Path != want[i].Path {
+			t.Errorf("Result %d: expected %s, got %s", i, want[i].Path, got[i].Path)
+		}
+	}
+
+	if len(want) > 1 {
+		for res, err := range search.Range(1) {
+			if err != nil {
+				t.Fatalf("Iteration failed: %v", err)
+			}
+			if res.Path != want[1].Path {
+				t.Errorf("Expected Range(1) to start at %s, got %s", want[1].Path, res.Path)
+			}
+			break
+		}
+	}
+
+	for _, err := range search.Range(-1) {
+		if err == nil {
+			t.Error("Expected an error for a negative start")
+		}
+	}
+}
+
+func TestSuggestionSearch_All(t *testing.T) {
+	archive, err := NewArchive(getTestSearchZimPath())
+	if err != nil {
+		t.Fatalf("Failed to open valid ZIM archive: %v", err)
+	}
+	defer archive.Close()
+
+	searcher, err := NewSuggestionSearcher(archive)
+	if err != nil {
+		t.Fatalf("Failed to create SuggestionSearcher: %v", err)
+	}
+	defer searcher.Close()
+
+	search, err := searcher.Suggest("markdown")
+	if err != nil {
+		t.Fatalf("Failed to execute Suggestion: %v", err)
+	}
+	defer search.Close()
+
+	want, err := search.GetResults(0, 10)
+	if err != nil {
+		t.Fatalf("Failed to retrieve results: %v", err)
+	}
+
+	n := 0
+	for res, err := range search.All() {
+		if err != nil {
+			t.Fatalf("Iteration failed: %v", err)
+		}
+		if n < len(want) && res.Path != want[n].Path {
+			t.Errorf("Suggestion %d: expected %s, got %s", n, want[n].Path, res.Path)
+		}
+		n++
+	}
+	if n < len(want) {
+		t.Errorf("Expected at least %d suggestions, got %d", len(want), n)
+	}
+}
+
+func TestSearchResult_Entry(t *testing.T) {
+	archive, err := NewArchive(getTestSearchZimPath())
+	if err != nil {
+		t.Fatalf("Failed to open valid ZIM archive: %v", err)
+	}
+	defer archive.Close()
+
+	if _, err := (SearchResult{}).Entry(); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound from a result without entry, got %v", err)
+	}
+
+	if archive.HasFulltextIndex() {
+		searcher, err := NewSearcher(archive)
+		if err != nil {
+			t.Fatalf("Failed to create Searcher: %v", err)
+		}
+		defer searcher.Close()
+
+		query, err := NewQuery("markdown")
+		if err != nil {
+			t.Fatalf("Failed to create Query: %v", err)
+		}
+		defer query.Close()
+
+		search, err := searcher.Search(query)
+		if err != nil {
+			t.Fatalf("Failed to execute Search: %v", err)
+		}
+		defer search.Close()
+
+		results, err := search.GetResults(0, 5)
+		if err != nil {
+			t.Fatalf("Failed to retrieve results: %v", err)
+		}
+		for _, res := range results {
+			checkResultEntry(t, res.Path, res.Mimetype, res.Size, res.Entry)
+		}
+	}
+
+	suggester, err := NewSuggestionSearcher(archive)
+	if err != nil {
+		t.Fatalf("Failed to create SuggestionSearcher: %v", err)
+	}
+	defer suggester.Close()
+
+	suggestion, err := suggester.Suggest("markdown")
+	if err != nil {
+		t.Fatalf("Failed to execute Suggestion: %v", err)
+	}
+	defer suggestion.Close()
+
+	suggestions, err := suggestion.GetResults(0, 5)
+	if err != nil {
+		t.Fatalf("Failed to retrieve results: %v", err)
+	}
+	for _, res := range suggestions {
+		checkResultEntry(t, res.Path, res.Mimetype, res.Size, res.Entry)
+	}
+}
+
+func checkResultEntry(t *testing.T, path, mimetype string, size uint64, entryOf func() (*Entry, error)) {
+	t.Helper()
+	entry, err := entryOf()
+	if err != nil {
+		t.Fatalf("Failed to get entry of result %s: %v", path, err)
+	}
+	defer entry.Close()
+
+	if entry.Path() != path {
+		t.Errorf("Expected entry path %q, got %q", path, entry.Path())
+	}
+
+	item, err := entry.GetItem(true)
+	if err != nil {
+		t.Fatalf("Failed to get item of result %s: %v", path, err)
+	}
+	defer item.Close()
+
+	if mimetype != item.GetMimetype() || size != item.GetSize() {
+		t.Errorf("Result %s: expected %s (%d bytes), got %s (%d bytes)", path, item.GetMimetype(), item.GetSize(), mimetype, size)
+	}
+}
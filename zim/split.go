@@ -0,0 +1,82 @@
+package zim
+
+/*
+#include "zim_wrapper.h"
+*/
+import "C"
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// IsMultiPart returns true if the archive is split in several files
+func (a *Archive) IsMultiPart() bool {
+	if !a.h.acquire() {
+		return false
+	}
+	defer a.h.release()
+
+	return bool(C.zim_archive_is_multipart(a.ptr))
+}
+
+// Parts lists the files making up the archive, in order. It returns nil for
+// archives not opened from a path.
+func (a *Archive) Parts() []string {
+	if a.path == "" {
+		return nil
+	}
+	if !a.IsMultiPart() {
+		return []string{a.path}
+	}
+	return splitArchiveParts(a.path)
+}
+
+// splitArchiveBase maps a part of a split archive (foo.zimaa) to the base
+// name libzim expects (foo.zim). Other paths are returned unchanged.
+func splitArchiveBase(path string) string {
+	ext := filepath.Ext(path)
+	if len(ext) == len(".zimaa") && strings.HasPrefix(ext, ".zim") && isPartSuffix(ext[4:]) {
+		return strings.TrimSuffix(path, ext[4:])
+	}
+	return path
+}
+
+// splitArchiveParts lists the existing parts of a split archive, stopping at
+// the first missing one like libzim does.
+func splitArchiveParts(base string) []string {
+	var parts []string
+	for first := 'a'; first <= 'z'; first++ {
+		for second := 'a'; second <= 'z'; second++ {
+			part := base + string(first) + string(second)
+			if _, err := os.Stat(part); err != nil {
+				return parts
+			}
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+// checkArchiveFile reports ErrIO if neither base nor the first part of a
+// split archive named after it can be opened, so that missing or unreadable
+// files are not taken for invalid archives
+func checkArchiveFile(op, base string) error {
+	f, err := os.Open(base)
+	if errors.Is(err, fs.ErrNotExist) {
+		if part, partErr := os.Open(base + "aa"); partErr == nil {
+			f, err = part, nil
+		}
+	}
+	if err != nil {
+		return &Error{Op: op, Kind: ErrIO, Message: err.Error()}
+	}
+	f.Close()
+	return nil
+}
+
+func isPartSuffix(s string) bool {
+	return len(s) == 2 && s[0] >= 'a' && s[0] <= 'z' && s[1] >= 'a' && s[1] <= 'z'
+}
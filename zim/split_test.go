@@ -0,0 +1,77 @@
+package zim
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestSplitArchiveBase(t *testing.T) {
+	cases := map[string]string{
+		"foo.zim":        "foo.zim",
+		"foo.zimaa":      "foo.zim",
+		"dir/foo.zimbz":  "dir/foo.zim",
+		"foo.zimAA":      "foo.zimAA",
+		"foo.zima":       "foo.zima",
+		"foo.zimaa.bak":  "foo.zimaa.bak",
+		"noextension":    "noextension",
+		"foo.zim/bar.aa": "foo.zim/bar.aa",
+	}
+	for in, want := range cases {
+		if got := splitArchiveBase(in); got != want {
+			t.Errorf("splitArchiveBase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestArchive_MultiPart(t *testing.T) {
+	content, err := os.ReadFile(getTestZimPath())
+	if err != nil {
+		t.Fatalf("Failed to read test ZIM file: %v", err)
+	}
+
+	// Split the test archive in three parts like zimsplit does
+	base := filepath.Join(t.TempDir(), "split.zim")
+	chunk := len(content)/3 + 1
+	var want []string
+	for i, suffix := range []string{"aa", "ab", "ac"} {
+		end := min((i+1)*chunk, len(content))
+		part := base + suffix
+		if err := os.WriteFile(part, content[i*chunk:end], 0o644); err != nil {
+			t.Fatalf("Failed to write part %s: %v", part, err)
+		}
+		want = append(want, part)
+	}
+
+	for _, path := range []string{base, base + "aa"} {
+		archive, err := NewArchive(path)
+		if err != nil {
+			t.Fatalf("Failed to open split archive from %s: %v", path, err)
+		}
+
+		if !archive.IsMultiPart() {
+			t.Errorf("Expected archive opened from %s to be multi-part", path)
+		}
+		if parts := archive.Parts(); !slices.Equal(parts, want) {
+			t.Errorf("Expected parts %v, got %v", want, parts)
+		}
+		if archive.GetEntryCount() == 0 {
+			t.Errorf("Expected split archive to have entries")
+		}
+		archive.Close()
+	}
+
+	single, err := NewArchive(getTestZimPath())
+	if err != nil {
+		t.Fatalf("Failed to open valid ZIM archive: %v", err)
+	}
+	defer single.Close()
+
+	if single.IsMultiPart() {
+		t.Errorf("Expected single file archive not to be multi-part")
+	}
+	if parts := single.Parts(); !slices.Equal(parts, []string{getTestZimPath()}) {
+		t.Errorf("Expected a single part, got %v", parts)
+	}
+}
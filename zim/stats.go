@@ -0,0 +1,95 @@
+package zim
+
+/*
+#include "zim_wrapper.h"
+*/
+import "C"
+import (
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// MimetypeStats aggregates the items of a single mimetype
+type MimetypeStats struct {
+	Items uint64 `json:"items"`
+	Size  uint64 `json:"size"` // Total content size in bytes
+}
+
+// Stats summarizes the content of an archive
+type Stats struct {
+	AllEntryCount uint64                   `json:"all_entry_count"` // Every entry, including metadata and indexes
+	EntryCount    uint64                   `json:"entry_count"`     // User entries
+	ArticleCount  uint64                   `json:"article_count"`   // Front articles
+	MediaCount    uint64                   `json:"media_count"`     // Image, video and audio items
+	RedirectCount uint64                   `json:"redirect_count"`  // User entries that are redirects
+	TotalSize     uint64                   `json:"total_size"`      // Content size of all user items
+	Counter       map[string]uint64        `json:"counter"`         // Parsed Counter metadata
+	Mimetypes     map[string]MimetypeStats `json:"mimetypes"`       // Computed from the items
+}
+
+// Stats computes statistics over the archive. Item sizes are read from the
+// content, which decompresses nearly every cluster: on large archives this
+// reads most of the file and takes a long time, during which Close blocks.
+func (a *Archive) Stats() (*Stats, error) {
+	if !a.h.acquire() {
+		return nil, closedError("Stats")
+	}
+	var cStats C.zim_archive_stats_t
+	var cerr C.zim_error_t
+	ok := bool(C.zim_archive_get_stats(a.ptr, &cStats, &cerr))
+	a.h.release()
+	if !ok {
+		return nil, newError("Stats", &cerr, nil)
+	}
+	defer C.zim_archive_stats_free(&cStats)
+
+	stats := &Stats{
+		AllEntryCount: uint64(cStats.all_entry_count),
+		EntryCount:    uint64(cStats.entry_count),
+		ArticleCount:  uint64(cStats.article_count),
+		MediaCount:    uint64(cStats.media_count),
+		RedirectCount: uint64(cStats.redirect_count),
+		Counter:       map[string]uint64{},
+		Mimetypes:     map[string]MimetypeStats{},
+	}
+
+	n := int(cStats.mimetype_count)
+	if n > 0 {
+		names := goStrings(cStats.mimetypes, cStats.mimetype_count)
+		items := unsafe.Slice((*uint64)(unsafe.Pointer(cStats.mimetype_items)), n)
+		sizes := unsafe.Slice((*uint64)(unsafe.Pointer(cStats.mimetype_sizes)), n)
+		for i, name := range names {
+			stats.Mimetypes[name] = MimetypeStats{Items: items[i], Size: sizes[i]}
+			stats.TotalSize += sizes[i]
+		}
+	}
+
+	if counter, err := a.GetMetadata("Counter"); err == nil {
+		stats.Counter = parseCounter(counter)
+	}
+	return stats, nil
+}
+
+// parseCounter parses the Counter metadata ("text/html=12;image/png=3").
+// Mimetype parameters also use ';' and '=', so parts without a numeric count
+// are joined to the following part.
+func parseCounter(counter string) map[string]uint64 {
+	out := map[string]uint64{}
+	pending := ""
+	for _, part := range strings.Split(counter, ";") {
+		idx := strings.LastIndex(part, "=")
+		if idx < 0 {
+			pending += part + ";"
+			continue
+		}
+		count, err := strconv.ParseUint(part[idx+1:], 10, 64)
+		if err != nil {
+			pending += part + ";"
+			continue
+		}
+		out[pending+part[:idx]] = count
+		pending = ""
+	}
+	return out
+}
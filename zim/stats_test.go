@@ -0,0 +1,71 @@
+package zim
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestArchive_Stats(t *testing.T) {
+	path := createTestArchive(t, "index.html",
+		testItem{path: "index.html", mimetype: "text/html", title: "Home", content: "<html>home</html>", front: true},
+		testItem{path: "page.html", mimetype: "text/html", title: "Page", content: "<html>page</html>", front: true},
+		testItem{path: "style.css", mimetype: "text/css", content: "body{}"},
+	)
+
+	archive, err := NewArchive(path)
+	if err != nil {
+		t.Fatalf("Failed to open generated ZIM archive: %v", err)
+	}
+	defer archive.Close()
+
+	stats, err := archive.Stats()
+	if err != nil {
+		t.Fatalf("Failed to compute stats: %v", err)
+	}
+	t.Logf("Stats: %+v", stats)
+
+	if stats.EntryCount != 3 {
+		t.Errorf("Expected 3 user entries, got %d", stats.EntryCount)
+	}
+	if stats.AllEntryCount < stats.EntryCount {
+		t.Errorf("Expected all entries (%d) >= user entries (%d)", stats.AllEntryCount, stats.EntryCount)
+	}
+	if stats.ArticleCount != 2 {
+		t.Errorf("Expected 2 articles, got %d", stats.ArticleCount)
+	}
+	if stats.RedirectCount != 0 {
+		t.Errorf("Expected no redirects, got %d", stats.RedirectCount)
+	}
+
+	html := stats.Mimetypes["text/html"]
+	if html.Items != 2 || html.Size != uint64(len("<html>home</html>")+len("<html>page</html>")) {
+		t.Errorf("Unexpected text/html stats: %+v", html)
+	}
+	if css := stats.Mimetypes["text/css"]; css.Items != 1 || css.Size != 6 {
+		t.Errorf("Unexpected text/css stats: %+v", css)
+	}
+	if stats.TotalSize != html.Size+6 {
+		t.Errorf("Expected total size %d, got %d", html.Size+6, stats.TotalSize)
+	}
+
+	// libzim writes the Counter metadata itself
+	if stats.Counter["text/html"] != 2 {
+		t.Errorf("Expected Counter to report 2 text/html items, got %v", stats.Counter)
+	}
+}
+
+func TestParseCounter(t *testing.T) {
+	got := parseCounter("text/html=12;text/html;raw=true=3;image/png=4;bogus")
+	want := map[string]uint64{
+		"text/html":          12,
+		"text/html;raw=true": 3,
+		"image/png":          4,
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	if got := parseCounter(""); len(got) != 0 {
+		t.Errorf("Expected empty counter, got %v", got)
+	}
+}
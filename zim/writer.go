@@ -7,7 +7,6 @@ package zim
 import "C"
 import (
 	"errors"
-	"runtime"
 	"unsafe"
 )
 
@@ -18,72 +17,152 @@ const (
 	CompressionZstd Compression = 5
 )
 
-// Creator represents the engine that builds a new ZIM archive
+// Creator represents the engine that builds a new ZIM archive. libzim's
+// creator is not thread-safe, so its methods run one at a time.
 type Creator struct {
-	ptr C.zim_creator_t
+	h        *handle
+	ptr      C.zim_creator_t
+	started  bool
+	finished bool
 }
 
 func NewCreator() (*Creator, error) {
-	ptr := C.zim_creator_new()
+	var cerr C.zim_error_t
+	ptr := C.zim_creator_new(&cerr)
 	if ptr == nil {
-		return nil, errors.New("failed to initialize ZIM creator")
+		return nil, newError("NewCreator", &cerr, nil)
 	}
 
 	c := &Creator{ptr: ptr}
-	runtime.SetFinalizer(c, (*Creator).Close)
+	c.h = newHandle("Creator", func() { C.zim_creator_free(ptr) })
+	track(c, c.h)
 	return c, nil
 }
 
 func (c *Creator) Close() {
-	if c.ptr != nil {
-		C.zim_creator_free(c.ptr)
-		c.ptr = nil
-	}
+	c.h.close()
 }
 
 func (c *Creator) ConfigVerbose(verbose bool) {
+	if !c.h.lock() {
+		return
+	}
+	defer c.h.unlock()
+
 	C.zim_creator_config_verbose(c.ptr, C.bool(verbose))
 }
 
 func (c *Creator) ConfigCompression(comp Compression) {
+	if !c.h.lock() {
+		return
+	}
+	defer c.h.unlock()
+
 	C.zim_creator_config_compression(c.ptr, C.int(comp))
 }
 
+// ConfigIndexing enables the fulltext and title indexes, built for the given
+// ISO 639-3 language (e.g. "eng"). Like the other options, it must be set
+// before StartZimCreation.
+func (c *Creator) ConfigIndexing(indexing bool, language string) {
+	if !c.h.lock() {
+		return
+	}
+	defer c.h.unlock()
+
+	cLang := C.CString(language)
+	defer C.free(unsafe.Pointer(cLang))
+	C.zim_creator_config_indexing(c.ptr, C.bool(indexing), cLang)
+}
+
+// checkStarted guards calls that libzim only accepts between
+// StartZimCreation and FinishZimCreation.
+func (c *Creator) checkStarted(op string) error {
+	switch {
+	case !c.started:
+		return &Error{Op: op, Kind: ErrCreatorState, Message: "ZIM creation not started"}
+	case c.finished:
+		return &Error{Op: op, Kind: ErrCreatorState, Message: "ZIM creation already finished"}
+	}
+	return nil
+}
+
 func (c *Creator) StartZimCreation(filepath string) error {
+	if !c.h.lock() {
+		return closedError("StartZimCreation")
+	}
+	defer c.h.unlock()
+
+	if c.started {
+		return &Error{Op: "StartZimCreation", Kind: ErrCreatorState, Message: "ZIM creation already started"}
+	}
+
 	cPath := C.CString(filepath)
 	defer C.free(unsafe.Pointer(cPath))
 
-	if !bool(C.zim_creator_start_zim_creation(c.ptr, cPath)) {
-		return errors.New("failed to start ZIM creation (is path writable?)")
+	var cerr C.zim_error_t
+	if !bool(C.zim_creator_start_zim_creation(c.ptr, cPath, &cerr)) {
+		return newError("StartZimCreation", &cerr, ErrIO)
 	}
+	c.started = true
 	return nil
 }
 
 func (c *Creator) SetMainPath(mainPath string) error {
+	if !c.h.lock() {
+		return closedError("SetMainPath")
+	}
+	defer c.h.unlock()
+
 	cPath := C.CString(mainPath)
 	defer C.free(unsafe.Pointer(cPath))
 
-	if !bool(C.zim_creator_set_main_path(c.ptr, cPath)) {
-		return errors.New("failed to set main path")
+	var cerr C.zim_error_t
+	if !bool(C.zim_creator_set_main_path(c.ptr, cPath, &cerr)) {
+		return newError("SetMainPath", &cerr, nil)
 	}
 	return nil
 }
 
 func (c *Creator) AddItem(item *WriterItem) error {
-	if !bool(C.zim_creator_add_item(c.ptr, item.ptr)) {
-		return errors.New("failed to add item to archive (duplicate path?)")
+	if !c.h.lock() {
+		return closedError("AddItem")
+	}
+	defer c.h.unlock()
+
+	if err := c.checkStarted("AddItem"); err != nil {
+		return err
+	}
+	if !item.h.acquire() {
+		return closedError("AddItem")
+	}
+	defer item.h.release()
+
+	var cerr C.zim_error_t
+	if !bool(C.zim_creator_add_item(c.ptr, item.ptr, &cerr)) {
+		return newError("AddItem", &cerr, nil)
 	}
 	return nil
 }
 
 func (c *Creator) AddMetadata(name, content string) error {
+	if !c.h.lock() {
+		return closedError("AddMetadata")
+	}
+	defer c.h.unlock()
+
+	if err := c.checkStarted("AddMetadata"); err != nil {
+		return err
+	}
+
 	cName := C.CString(name)
 	cContent := C.CString(content)
 	defer C.free(unsafe.Pointer(cName))
 	defer C.free(unsafe.Pointer(cContent))
 
-	if !bool(C.zim_creator_add_metadata(c.ptr, cName, cContent)) {
-		return errors.New("failed to add metadata")
+	var cerr C.zim_error_t
+	if !bool(C.zim_creator_add_metadata(c.ptr, cName, cContent, &cerr)) {
+		return newError("AddMetadata", &cerr, nil)
 	}
 	return nil
 }
@@ -92,23 +171,43 @@ func (c *Creator) AddIllustration(size uint, content []byte) error {
 	if len(content) == 0 {
 		return errors.New("illustration content cannot be empty")
 	}
+	if !c.h.lock() {
+		return closedError("AddIllustration")
+	}
+	defer c.h.unlock()
+	if err := c.checkStarted("AddIllustration"); err != nil {
+		return err
+	}
 
+	var cerr C.zim_error_t
 	cContent := (*C.char)(unsafe.Pointer(&content[0]))
-	if !bool(C.zim_creator_add_illustration(c.ptr, C.uint(size), cContent, C.uint64_t(len(content)))) {
-		return errors.New("failed to add illustration")
+	if !bool(C.zim_creator_add_illustration(c.ptr, C.uint(size), cContent, C.uint64_t(len(content)), &cerr)) {
+		return newError("AddIllustration", &cerr, nil)
 	}
 	return nil
 }
 
 func (c *Creator) FinishZimCreation() error {
-	if !bool(C.zim_creator_finish_zim_creation(c.ptr)) {
-		return errors.New("failed to finalize and finish ZIM creation")
+	if !c.h.lock() {
+		return closedError("FinishZimCreation")
+	}
+	defer c.h.unlock()
+
+	if err := c.checkStarted("FinishZimCreation"); err != nil {
+		return err
+	}
+
+	var cerr C.zim_error_t
+	if !bool(C.zim_creator_finish_zim_creation(c.ptr, &cerr)) {
+		return newError("FinishZimCreation", &cerr, nil)
 	}
+	c.finished = true
 	return nil
 }
 
 // WriterItem represents an entry pending insertion into a ZIM archive
 type WriterItem struct {
+	h   *handle
 	ptr C.zim_writer_item_t
 }
 
@@ -125,14 +224,21 @@ func NewStringItem(path, mimetype, title string, content []byte, isFrontArticle
 		cContent = (*C.char)(unsafe.Pointer(&content[0]))
 	}
 
-	ptr := C.zim_writer_string_item_new(cPath, cMime, cTitle, cContent, C.uint64_t(len(content)), C.bool(isFrontArticle))
+	var cerr C.zim_error_t
+	ptr := C.zim_writer_string_item_new(cPath, cMime, cTitle, cContent, C.uint64_t(len(content)), C.bool(isFrontArticle), &cerr)
 	if ptr == nil {
-		return nil, errors.New("failed to create string item")
+		return nil, newError("NewStringItem", &cerr, nil)
 	}
 
+	return newWriterItem(ptr), nil
+}
+
+// newWriterItem wraps an item pending insertion
+func newWriterItem(ptr C.zim_writer_item_t) *WriterItem {
 	item := &WriterItem{ptr: ptr}
-	runtime.SetFinalizer(item, (*WriterItem).Close)
-	return item, nil
+	item.h = newHandle("WriterItem", func() { C.zim_writer_item_free(ptr) })
+	track(item, item.h)
+	return item
 }
 
 func NewFileItem(path, mimetype, title, filepath string, isFrontArticle bool) (*WriterItem, error) {
@@ -145,19 +251,15 @@ func NewFileItem(path, mimetype, title, filepath string, isFrontArticle bool) (*
 	defer C.free(unsafe.Pointer(cTitle))
 	defer C.free(unsafe.Pointer(cFilepath))
 
-	ptr := C.zim_writer_file_item_new(cPath, cMime, cTitle, cFilepath, C.bool(isFrontArticle))
+	var cerr C.zim_error_t
+	ptr := C.zim_writer_file_item_new(cPath, cMime, cTitle, cFilepath, C.bool(isFrontArticle), &cerr)
 	if ptr == nil {
-		return nil, errors.New("failed to create file item")
+		return nil, newError("NewFileItem", &cerr, nil)
 	}
 
-	item := &WriterItem{ptr: ptr}
-	runtime.SetFinalizer(item, (*WriterItem).Close)
-	return item, nil
+	return newWriterItem(ptr), nil
 }
 
 func (i *WriterItem) Close() {
-	if i.ptr != nil {
-		C.zim_writer_item_free(i.ptr)
-		i.ptr = nil
-	}
+	i.h.close()
 }
@@ -1,8 +1,11 @@
 package zim
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 )
 
@@ -107,5 +110,186 @@ func TestZIMCreator_RoundTrip(t *testing.T) {
 		t.Errorf("Content mismatch. Expected %q, got %q", string(mainPageContent), string(readData))
 	}
 
+	md, err := archive.Metadata()
+	if err != nil {
+		t.Fatalf("Failed to read metadata: %v", err)
+	}
+	if md.Title != "Go Roundtrip Test Archive" {
+		t.Errorf("Expected Title metadata to round-trip, got %q", md.Title)
+	}
+	if md.Language != "eng" {
+		t.Errorf("Expected Language metadata to round-trip, got %q", md.Language)
+	}
+
 	t.Log("Round-trip Creation -> Write -> Read was 100% successful!")
 }
+
+func TestZIMCreator_Errors(t *testing.T) {
+	outPath := filepath.Join(t.TempDir(), "errors.zim")
+
+	creator, err := NewCreator()
+	if err != nil {
+		t.Fatalf("Failed to create ZIM Creator: %v", err)
+	}
+	defer creator.Close()
+
+	item, err := NewStringItem("index.html", "text/html", "Home", []byte("<html></html>"), true)
+	if err != nil {
+		t.Fatalf("Failed to create string item: %v", err)
+	}
+	defer item.Close()
+
+	// Adding before StartZimCreation must not reach libzim
+	if err := creator.AddItem(item); !errors.Is(err, ErrCreatorState) {
+		t.Errorf("Expected ErrCreatorState before start, got %v", err)
+	}
+
+	creator.ConfigCompression(CompressionNone)
+	if err := creator.StartZimCreation(outPath); err != nil {
+		t.Fatalf("Failed to start creation: %v", err)
+	}
+
+	if err := creator.AddItem(item); err != nil {
+		t.Fatalf("Failed to add item: %v", err)
+	}
+
+	dup, err := NewStringItem("index.html", "text/html", "Duplicate", []byte("<html></html>"), true)
+	if err != nil {
+		t.Fatalf("Failed to create duplicate item: %v", err)
+	}
+	defer dup.Close()
+
+	err = creator.AddItem(dup)
+	if !errors.Is(err, ErrDuplicatePath) {
+		t.Errorf("Expected ErrDuplicatePath, got %v", err)
+	}
+
+	var zerr *Error
+	if !errors.As(err, &zerr) || zerr.Op != "AddItem" {
+		t.Errorf("Expected *Error with Op AddItem, got %#v", err)
+	}
+
+	if err := creator.FinishZimCreation(); err != nil {
+		t.Fatalf("Failed to finish ZIM creation: %v", err)
+	}
+
+	if err := creator.AddMetadata("Title", "Too late"); !errors.Is(err, ErrCreatorState) {
+		t.Errorf("Expected ErrCreatorState after finish, got %v", err)
+	}
+}
+
+// testItem describes an entry written by createTestArchive
+type testItem struct {
+	path     string
+	mimetype string
+	title    string
+	content  string
+	front    bool
+}
+
+// createTestArchive writes a small uncompressed archive with the given items
+// in a temporary directory and returns its path.
+func createTestArchive(t *testing.T, mainPath string, items ...testItem) string {
+	t.Helper()
+	return buildTestArchive(t, false, mainPath, items)
+}
+
+// createIndexedTestArchive is like createTestArchive but also builds the
+// fulltext and title indexes
+func createIndexedTestArchive(t *testing.T, items ...testItem) string {
+	t.Helper()
+	return buildTestArchive(t, true, "", items)
+}
+
+func buildTestArchive(t *testing.T, indexing bool, mainPath string, items []testItem) string {
+	t.Helper()
+	outPath := filepath.Join(t.TempDir(), "test.zim")
+
+	creator, err := NewCreator()
+	if err != nil {
+		t.Fatalf("Failed to create ZIM Creator: %v", err)
+	}
+	defer creator.Close()
+
+	creator.ConfigCompression(CompressionNone)
+	if indexing {
+		creator.ConfigIndexing(true, "eng")
+	}
+	if err := creator.StartZimCreation(outPath); err != nil {
+		t.Fatalf("Failed to start creation: %v", err)
+	}
+
+	for _, it := range items {
+		item, err := NewStringItem(it.path, it.mimetype, it.title, []byte(it.content), it.front)
+		if err != nil {
+			t.Fatalf("Failed to create item %s: %v", it.path, err)
+		}
+		if err := creator.AddItem(item); err != nil {
+			t.Fatalf("Failed to add item %s: %v", it.path, err)
+		}
+		item.Close()
+	}
+
+	if mainPath != "" {
+		if err := creator.SetMainPath(mainPath); err != nil {
+			t.Fatalf("Failed to set main path: %v", err)
+		}
+	}
+	if err := creator.FinishZimCreation(); err != nil {
+		t.Fatalf("Failed to finish ZIM creation: %v", err)
+	}
+	return outPath
+}
+
+func TestZIMCreator_Concurrent(t *testing.T) {
+	outPath := filepath.Join(t.TempDir(), "concurrent.zim")
+
+	creator, err := NewCreator()
+	if err != nil {
+		t.Fatalf("Failed to create ZIM Creator: %v", err)
+	}
+	defer creator.Close()
+
+	creator.ConfigCompression(CompressionNone)
+	if err := creator.StartZimCreation(outPath); err != nil {
+		t.Fatalf("Failed to start creation: %v", err)
+	}
+
+	const workers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*workers)
+	for n := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			item, err := NewStringItem(fmt.Sprintf("page%d.html", n), "text/html", "", []byte("<p>page</p>"), false)
+			if err != nil {
+				errs <- err
+				return
+			}
+			defer item.Close()
+			errs <- creator.AddItem(item)
+			errs <- creator.AddMetadata(fmt.Sprintf("Custom%d", n), "value")
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Concurrent add failed: %v", err)
+		}
+	}
+
+	if err := creator.FinishZimCreation(); err != nil {
+		t.Fatalf("Failed to finish creation: %v", err)
+	}
+
+	archive, err := NewArchive(outPath)
+	if err != nil {
+		t.Fatalf("Failed to open generated archive: %v", err)
+	}
+	defer archive.Close()
+	if count := archive.GetEntryCount(); count != workers {
+		t.Errorf("Expected %d entries, got %d", workers, count)
+	}
+}
@@ -2,124 +2,417 @@ package zim
 
 /*
 #cgo CXXFLAGS: -std=c++11
-#cgo LDFLAGS: -lzim
+#cgo LDFLAGS: -lzim -licuuc
 #include <stdlib.h>
 #include "zim_wrapper.h"
 */
 import "C"
 import (
-	"errors"
+	"bytes"
+	"os"
 	"runtime"
 	"unsafe"
 )
 
 // Archive represents a readable ZIM archive
 type Archive struct {
-	ptr C.zim_archive_t
+	h    *handle
+	ptr  C.zim_archive_t
+	path string // Path the archive was opened from, empty for descriptors
 }
 
-// NewArchive opens a ZIM archive from the given file path
+// NewArchive opens a ZIM archive from the given file path.
+// Split archives (foo.zimaa, foo.zimab, ...) are opened by passing either
+// the base name (foo.zim) or any of their parts.
 func NewArchive(path string) (*Archive, error) {
+	path = splitArchiveBase(path)
+	if err := checkArchiveFile("NewArchive", path); err != nil {
+		return nil, err
+	}
 	cPath := C.CString(path)
 	defer C.free(unsafe.Pointer(cPath))
 
-	ptr := C.zim_archive_new(cPath)
+	var cerr C.zim_error_t
+	ptr := C.zim_archive_new(cPath, &cerr)
 	if ptr == nil {
-		return nil, errors.New("failed to open archive or invalid format")
+		return nil, newError("NewArchive", &cerr, ErrInvalidArchive)
 	}
 
-	arch := &Archive{ptr: ptr}
-	runtime.SetFinalizer(arch, (*Archive).Close)
-	return arch, nil
+	return newArchive(ptr, path, nil), nil
 }
 
-// Close frees the underlying C++ archive resources
-func (a *Archive) Close() {
-	if a.ptr != nil {
-		C.zim_archive_free(a.ptr)
-		a.ptr = nil
+// newArchive wraps an opened archive. cleanup, if not nil, releases the
+// backing storage of in-memory archives after the archive is freed.
+func newArchive(ptr C.zim_archive_t, path string, cleanup func()) *Archive {
+	arch := &Archive{ptr: ptr, path: path}
+	arch.h = newHandle("Archive", func() {
+		C.zim_archive_free(ptr)
+		if cleanup != nil {
+			cleanup()
+		}
+	})
+	track(arch, arch.h)
+	return arch
+}
+
+// NewArchiveFromFile opens a ZIM archive from an already opened file.
+// libzim reopens the file through its descriptor, so f is not retained and
+// may be closed once NewArchiveFromFile returns.
+func NewArchiveFromFile(f *os.File) (*Archive, error) {
+	return newArchiveFromFile("NewArchiveFromFile", f, nil)
+}
+
+func newArchiveFromFile(op string, f *os.File, cleanup func()) (*Archive, error) {
+	var cerr C.zim_error_t
+	ptr := C.zim_archive_new_from_fd(C.int(f.Fd()), &cerr)
+	runtime.KeepAlive(f)
+	if ptr == nil {
+		return nil, newError(op, &cerr, ErrInvalidArchive)
+	}
+
+	return newArchive(ptr, "", cleanup), nil
+}
+
+// NewArchiveFromFileRange opens a ZIM archive stored in the byte range
+// [offset, offset+size) of f, e.g. a ZIM embedded in a larger container file.
+// As with NewArchiveFromFile, f may be closed once the call returns.
+func NewArchiveFromFileRange(f *os.File, offset, size int64) (*Archive, error) {
+	if offset < 0 || size <= 0 {
+		return nil, &Error{Op: "NewArchiveFromFileRange", Kind: ErrInvalidArchive, Message: "invalid offset or size"}
+	}
+
+	var cerr C.zim_error_t
+	ptr := C.zim_archive_new_from_fd_range(C.int(f.Fd()), C.uint64_t(offset), C.uint64_t(size), &cerr)
+	runtime.KeepAlive(f)
+	if ptr == nil {
+		return nil, newError("NewArchiveFromFileRange", &cerr, ErrInvalidArchive)
 	}
+
+	return newArchive(ptr, "", nil), nil
+}
+
+// Close frees the underlying C++ archive resources. It waits for the calls
+// in progress on the archive and its entries, items and searchers to return.
+// Afterwards, methods of the archive and of the objects created from it
+// return ErrClosed, or a zero value when they have no error result.
+// Close is safe to call several times and from several goroutines.
+func (a *Archive) Close() {
+	a.h.close()
 }
 
 // GetEntryCount returns the number of user entries
 func (a *Archive) GetEntryCount() uint64 {
+	if !a.h.acquire() {
+		return 0
+	}
+	defer a.h.release()
+
 	return uint64(C.zim_archive_get_entry_count(a.ptr))
 }
 
+// UUID returns the archive UUID in its canonical textual form
+func (a *Archive) UUID() string {
+	if !a.h.acquire() {
+		return ""
+	}
+	defer a.h.release()
+
+	cStr := C.zim_archive_get_uuid(a.ptr)
+	if cStr == nil {
+		return ""
+	}
+	defer C.free(unsafe.Pointer(cStr))
+	return C.GoString(cStr)
+}
+
+// FileSize returns the size of the archive in bytes (all parts included)
+func (a *Archive) FileSize() uint64 {
+	if !a.h.acquire() {
+		return 0
+	}
+	defer a.h.release()
+
+	return uint64(C.zim_archive_get_filesize(a.ptr))
+}
+
 type Entry struct {
-	ptr C.zim_entry_t
+	h       *handle
+	ptr     C.zim_entry_t
+	archive *Archive // Keeps the archive alive while the entry is reachable
+}
+
+// newEntry wraps an entry read from a
+func (a *Archive) newEntry(ptr C.zim_entry_t) *Entry {
+	entry := &Entry{ptr: ptr, archive: a}
+	entry.h = newHandle("Entry", func() { C.zim_entry_free(ptr) }, a.h)
+	track(entry, entry.h)
+	return entry
 }
 
 // GetEntryByPath retrieves an entry by its URL path inside the archive
 func (a *Archive) GetEntryByPath(path string) (*Entry, error) {
+	if !a.h.acquire() {
+		return nil, closedError("GetEntryByPath")
+	}
+	defer a.h.release()
+
 	cPath := C.CString(path)
 	defer C.free(unsafe.Pointer(cPath))
 
-	ptr := C.zim_archive_get_entry_by_path(a.ptr, cPath)
+	var cerr C.zim_error_t
+	ptr := C.zim_archive_get_entry_by_path(a.ptr, cPath, &cerr)
 	if ptr == nil {
-		return nil, errors.New("entry not found")
+		return nil, newError("GetEntryByPath", &cerr, ErrNotFound)
 	}
 
-	entry := &Entry{ptr: ptr}
-	runtime.SetFinalizer(entry, (*Entry).Close)
-	return entry, nil
+	return a.newEntry(ptr), nil
 }
 
 // GetMainEntry retrieves the default index/home page of the ZIM archive
 func (a *Archive) GetMainEntry() (*Entry, error) {
-	ptr := C.zim_archive_get_main_entry(a.ptr)
+	if !a.h.acquire() {
+		return nil, closedError("GetMainEntry")
+	}
+	defer a.h.release()
+
+	var cerr C.zim_error_t
+	ptr := C.zim_archive_get_main_entry(a.ptr, &cerr)
 	if ptr == nil {
-		return nil, errors.New("main entry not found or archive has no main entry")
+		return nil, newError("GetMainEntry", &cerr, ErrNotFound)
 	}
 
-	entry := &Entry{ptr: ptr}
-	runtime.SetFinalizer(entry, (*Entry).Close)
-	return entry, nil
+	return a.newEntry(ptr), nil
 }
 
 // GetEntryByIndex retrieves an entry by its numerical index (sorted by path)
 func (a *Archive) GetEntryByIndex(idx uint32) (*Entry, error) {
-	ptr := C.zim_archive_get_entry_by_index(a.ptr, C.uint32_t(idx))
+	if !a.h.acquire() {
+		return nil, closedError("GetEntryByIndex")
+	}
+	defer a.h.release()
+
+	var cerr C.zim_error_t
+	ptr := C.zim_archive_get_entry_by_index(a.ptr, C.uint32_t(idx), &cerr)
+	if ptr == nil {
+		return nil, newError("GetEntryByIndex", &cerr, ErrNotFound)
+	}
+
+	return a.newEntry(ptr), nil
+}
+
+// HasEntryByPath returns true if an entry exists at the given path
+func (a *Archive) HasEntryByPath(path string) bool {
+	if !a.h.acquire() {
+		return false
+	}
+	defer a.h.release()
+
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+
+	return bool(C.zim_archive_has_entry_by_path(a.ptr, cPath))
+}
+
+// HasEntryByTitle returns true if a front article has the given title
+func (a *Archive) HasEntryByTitle(title string) bool {
+	if !a.h.acquire() {
+		return false
+	}
+	defer a.h.release()
+
+	cTitle := C.CString(title)
+	defer C.free(unsafe.Pointer(cTitle))
+
+	return bool(C.zim_archive_has_entry_by_title(a.ptr, cTitle))
+}
+
+// GetEntryByTitle retrieves a front article by its exact title
+func (a *Archive) GetEntryByTitle(title string) (*Entry, error) {
+	if !a.h.acquire() {
+		return nil, closedError("GetEntryByTitle")
+	}
+	defer a.h.release()
+
+	cTitle := C.CString(title)
+	defer C.free(unsafe.Pointer(cTitle))
+
+	var cerr C.zim_error_t
+	ptr := C.zim_archive_get_entry_by_title(a.ptr, cTitle, &cerr)
 	if ptr == nil {
-		return nil, errors.New("entry not found at the specified index")
+		return nil, newError("GetEntryByTitle", &cerr, ErrNotFound)
 	}
 
-	entry := &Entry{ptr: ptr}
-	runtime.SetFinalizer(entry, (*Entry).Close)
-	return entry, nil
+	return a.newEntry(ptr), nil
 }
 
+// GetEntryByTitleIndex retrieves an entry by its numerical index in the title
+// ordered index
+func (a *Archive) GetEntryByTitleIndex(idx uint32) (*Entry, error) {
+	if !a.h.acquire() {
+		return nil, closedError("GetEntryByTitleIndex")
+	}
+	defer a.h.release()
+
+	var cerr C.zim_error_t
+	ptr := C.zim_archive_get_entry_by_title_index(a.ptr, C.uint32_t(idx), &cerr)
+	if ptr == nil {
+		return nil, newError("GetEntryByTitleIndex", &cerr, ErrNotFound)
+	}
+
+	return a.newEntry(ptr), nil
+}
+
+// Close frees the entry. It is safe to call several times.
 func (e *Entry) Close() {
-	if e.ptr != nil {
-		C.zim_entry_free(e.ptr)
-		e.ptr = nil
+	e.h.close()
+}
+
+// Path returns the path of the entry itself, without following redirects
+func (e *Entry) Path() string {
+	if !e.h.acquire() {
+		return ""
+	}
+	defer e.h.release()
+
+	cStr := C.zim_entry_get_path(e.ptr)
+	if cStr == nil {
+		return ""
+	}
+	defer C.free(unsafe.Pointer(cStr))
+	return C.GoString(cStr)
+}
+
+// Title returns the title of the entry itself, without following redirects
+func (e *Entry) Title() string {
+	if !e.h.acquire() {
+		return ""
+	}
+	defer e.h.release()
+
+	cStr := C.zim_entry_get_title(e.ptr)
+	if cStr == nil {
+		return ""
+	}
+	defer C.free(unsafe.Pointer(cStr))
+	return C.GoString(cStr)
+}
+
+// Index returns the index of the entry in the path ordered index
+func (e *Entry) Index() uint32 {
+	if !e.h.acquire() {
+		return 0
+	}
+	defer e.h.release()
+
+	return uint32(C.zim_entry_get_index(e.ptr))
+}
+
+// IsRedirect returns true if the entry points to another entry instead of
+// holding content
+func (e *Entry) IsRedirect() bool {
+	if !e.h.acquire() {
+		return false
 	}
+	defer e.h.release()
+
+	return bool(C.zim_entry_is_redirect(e.ptr))
+}
+
+// RedirectEntry returns the entry this redirect points to, which may itself
+// be a redirect. It returns ErrNotFound if the entry is not a redirect.
+func (e *Entry) RedirectEntry() (*Entry, error) {
+	if !e.h.acquire() {
+		return nil, closedError("RedirectEntry")
+	}
+	defer e.h.release()
+
+	var cerr C.zim_error_t
+	ptr := C.zim_entry_get_redirect_entry(e.ptr, &cerr)
+	if ptr == nil {
+		return nil, newError("RedirectEntry", &cerr, nil)
+	}
+
+	return e.archive.newEntry(ptr), nil
+}
+
+// RedirectChain follows the redirects starting at e and returns the visited
+// entries, the last one being the final non-redirect target. The chain is
+// empty if e is not a redirect. It returns ErrRedirectLoop if the chain never
+// reaches a non-redirect entry. The caller must close the returned entries.
+func (e *Entry) RedirectChain() ([]*Entry, error) {
+	if !e.h.acquire() {
+		return nil, closedError("RedirectChain")
+	}
+	e.h.release()
+
+	var chain []*Entry
+	closeChain := func() {
+		for _, entry := range chain {
+			entry.Close()
+		}
+	}
+
+	visited := map[uint32]bool{e.Index(): true}
+	current := e
+	for current.IsRedirect() {
+		next, err := current.RedirectEntry()
+		if err != nil {
+			closeChain()
+			return nil, err
+		}
+		chain = append(chain, next)
+
+		if visited[next.Index()] {
+			closeChain()
+			return nil, &Error{Op: "RedirectChain", Kind: ErrRedirectLoop, Message: "redirect loop at " + e.Path()}
+		}
+		visited[next.Index()] = true
+		current = next
+	}
+	return chain, nil
 }
 
 type Item struct {
-	ptr C.zim_item_t
+	h       *handle
+	ptr     C.zim_item_t
+	archive *Archive // Keeps the archive alive while the item is reachable
+}
+
+// newItem wraps an item read from a
+func (a *Archive) newItem(ptr C.zim_item_t) *Item {
+	item := &Item{ptr: ptr, archive: a}
+	item.h = newHandle("Item", func() { C.zim_item_free(ptr) }, a.h)
+	track(item, item.h)
+	return item
 }
 
 // GetItem resolves the entry payload. If 'follow' is true, it automatically resolves redirects.
 func (e *Entry) GetItem(follow bool) (*Item, error) {
-	ptr := C.zim_entry_get_item(e.ptr, C.bool(follow))
+	if !e.h.acquire() {
+		return nil, closedError("GetItem")
+	}
+	defer e.h.release()
+
+	var cerr C.zim_error_t
+	ptr := C.zim_entry_get_item(e.ptr, C.bool(follow), &cerr)
 	if ptr == nil {
-		return nil, errors.New("failed to retrieve item")
+		return nil, newError("GetItem", &cerr, nil)
 	}
 
-	item := &Item{ptr: ptr}
-	runtime.SetFinalizer(item, (*Item).Close)
-	return item, nil
+	return e.archive.newItem(ptr), nil
 }
 
+// Close frees the item. It is safe to call several times.
 func (i *Item) Close() {
-	if i.ptr != nil {
-		C.zim_item_free(i.ptr)
-		i.ptr = nil
-	}
+	i.h.close()
 }
 
 func (i *Item) GetPath() string {
+	if !i.h.acquire() {
+		return ""
+	}
+	defer i.h.release()
+
 	cStr := C.zim_item_get_path(i.ptr)
 	if cStr == nil {
 		return ""
@@ -129,6 +422,11 @@ func (i *Item) GetPath() string {
 }
 
 func (i *Item) GetTitle() string {
+	if !i.h.acquire() {
+		return ""
+	}
+	defer i.h.release()
+
 	cStr := C.zim_item_get_title(i.ptr)
 	if cStr == nil {
 		return ""
@@ -138,6 +436,11 @@ func (i *Item) GetTitle() string {
 }
 
 func (i *Item) GetMimetype() string {
+	if !i.h.acquire() {
+		return ""
+	}
+	defer i.h.release()
+
 	cStr := C.zim_item_get_mimetype(i.ptr)
 	if cStr == nil {
 		return ""
@@ -147,18 +450,23 @@ func (i *Item) GetMimetype() string {
 }
 
 func (i *Item) GetSize() uint64 {
+	if !i.h.acquire() {
+		return 0
+	}
+	defer i.h.release()
+
 	return uint64(C.zim_item_get_size(i.ptr))
 }
 
-// GetData reads the blob buffer for this item and returns it as a byte slice.
+// GetData reads the blob buffer for this item and returns a copy of it as a
+// byte slice. Use GetBlob to access the content without copying it.
 func (i *Item) GetData() []byte {
-	var size C.uint64_t
-	cData := C.zim_item_get_data(i.ptr, &size)
-	if cData == nil {
+	blob, err := i.GetBlob()
+	if err != nil {
 		return nil
 	}
-	defer C.free(unsafe.Pointer(cData))
+	defer blob.Close()
 
-	// Convert C allocation directly into Go slice
-	return C.GoBytes(unsafe.Pointer(cData), C.int(size))
+	// Single copy from the libzim buffer into Go memory
+	return bytes.Clone(blob.Bytes())
 }
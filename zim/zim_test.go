@@ -1,6 +1,9 @@
 package zim
 
 import (
+	"bytes"
+	"errors"
+	"os"
 	"path/filepath"
 	"testing"
 )
@@ -35,6 +38,19 @@ func TestZIMArchive_OpenInvalid(t *testing.T) {
 	if err == nil {
 		t.Errorf("Expected an error when opening a non-existent ZIM archive, got nil")
 	}
+	if !errors.Is(err, ErrIO) {
+		t.Errorf("Expected ErrIO for a missing file, got %v", err)
+	}
+}
+
+func TestZIMArchive_OpenNotZim(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "not_a_zim.zim")
+	if err := os.WriteFile(path, []byte("definitely not a zim archive"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if _, err := NewArchive(path); !errors.Is(err, ErrInvalidArchive) {
+		t.Errorf("Expected ErrInvalidArchive, got %v", err)
+	}
 }
 
 func TestZIMArchive_GetNonExistentEntry(t *testing.T) {
@@ -49,4 +65,191 @@ func TestZIMArchive_GetNonExistentEntry(t *testing.T) {
 	if err == nil {
 		t.Errorf("Expected an error when fetching a non-existent entry, got nil")
 	}
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	var zerr *Error
+	if !errors.As(err, &zerr) || zerr.Op != "GetEntryByPath" {
+		t.Errorf("Expected *Error with Op GetEntryByPath, got %#v", err)
+	}
+}
+
+func TestZIMArchive_OpenFromFile(t *testing.T) {
+	f, err := os.Open(getTestZimPath())
+	if err != nil {
+		t.Fatalf("Failed to open test ZIM file: %v", err)
+	}
+
+	archive, err := NewArchiveFromFile(f)
+	if err != nil {
+		t.Fatalf("Failed to open ZIM archive from file: %v", err)
+	}
+	defer archive.Close()
+
+	// The archive must stay usable once the caller's file is closed
+	f.Close()
+
+	reference, err := NewArchive(getTestZimPath())
+	if err != nil {
+		t.Fatalf("Failed to open valid ZIM archive: %v", err)
+	}
+	defer reference.Close()
+
+	if archive.GetEntryCount() != reference.GetEntryCount() {
+		t.Errorf("Expected %d entries, got %d", reference.GetEntryCount(), archive.GetEntryCount())
+	}
+}
+
+func TestZIMArchive_OpenFromFileRange(t *testing.T) {
+	content, err := os.ReadFile(getTestZimPath())
+	if err != nil {
+		t.Fatalf("Failed to read test ZIM file: %v", err)
+	}
+
+	// Embed the archive between a header and a trailer
+	header := bytes.Repeat([]byte{0xAA}, 4096)
+	trailer := bytes.Repeat([]byte{0x55}, 1234)
+	containerPath := filepath.Join(t.TempDir(), "container.bin")
+	blob := append(append(append([]byte{}, header...), content...), trailer...)
+	if err := os.WriteFile(containerPath, blob, 0o644); err != nil {
+		t.Fatalf("Failed to write container file: %v", err)
+	}
+
+	f, err := os.Open(containerPath)
+	if err != nil {
+		t.Fatalf("Failed to open container file: %v", err)
+	}
+	defer f.Close()
+
+	archive, err := NewArchiveFromFileRange(f, int64(len(header)), int64(len(content)))
+	if err != nil {
+		t.Fatalf("Failed to open embedded ZIM archive: %v", err)
+	}
+	defer archive.Close()
+
+	if archive.GetEntryCount() == 0 {
+		t.Errorf("Expected embedded archive to have entries")
+	}
+
+	_, err = NewArchiveFromFileRange(f, 0, 0)
+	if !errors.Is(err, ErrInvalidArchive) {
+		t.Errorf("Expected ErrInvalidArchive for an empty range, got %v", err)
+	}
+}
+
+func TestZIMArchive_EntryByTitle(t *testing.T) {
+	path := createTestArchive(t, "index.html",
+		testItem{path: "index.html", mimetype: "text/html", title: "Home", content: "<html>home</html>", front: true},
+		testItem{path: "wiki/some_article.html", mimetype: "text/html", title: "Some Article", content: "<html>article</html>", front: true},
+	)
+
+	archive, err := NewArchive(path)
+	if err != nil {
+		t.Fatalf("Failed to open generated ZIM archive: %v", err)
+	}
+	defer archive.Close()
+
+	if !archive.HasEntryByPath("wiki/some_article.html") {
+		t.Errorf("Expected HasEntryByPath to find wiki/some_article.html")
+	}
+	if !archive.HasEntryByTitle("Some Article") {
+		t.Errorf("Expected HasEntryByTitle to find Some Article")
+	}
+	if archive.HasEntryByTitle("Missing Article") {
+		t.Errorf("Expected HasEntryByTitle not to find Missing Article")
+	}
+
+	entry, err := archive.GetEntryByTitle("Some Article")
+	if err != nil {
+		t.Fatalf("Failed to get entry by title: %v", err)
+	}
+	defer entry.Close()
+
+	item, err := entry.GetItem(true)
+	if err != nil {
+		t.Fatalf("Failed to get item: %v", err)
+	}
+	defer item.Close()
+
+	if item.GetPath() != "wiki/some_article.html" {
+		t.Errorf("Expected path wiki/some_article.html, got %q", item.GetPath())
+	}
+
+	_, err = archive.GetEntryByTitle("Missing Article")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a missing title, got %v", err)
+	}
+
+	// Titles sort "Home" before "Some Article"
+	first, err := archive.GetEntryByTitleIndex(0)
+	if err != nil {
+		t.Fatalf("Failed to get entry by title index: %v", err)
+	}
+	defer first.Close()
+
+	firstItem, err := first.GetItem(true)
+	if err != nil {
+		t.Fatalf("Failed to get item: %v", err)
+	}
+	defer firstItem.Close()
+
+	if firstItem.GetTitle() != "Home" {
+		t.Errorf("Expected first title to be Home, got %q", firstItem.GetTitle())
+	}
+
+	_, err = archive.GetEntryByTitleIndex(1000)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an out of range title index, got %v", err)
+	}
+}
+
+func TestZIMEntry_Accessors(t *testing.T) {
+	archive, err := NewArchive(getTestZimPath())
+	if err != nil {
+		t.Fatalf("Failed to open valid ZIM archive: %v", err)
+	}
+	defer archive.Close()
+
+	redirects := 0
+	for entry, err := range archive.EntriesByPath() {
+		if err != nil {
+			t.Fatalf("Iteration failed: %v", err)
+		}
+
+		if entry.Path() == "" {
+			t.Errorf("Expected entry %d to have a path", entry.Index())
+		}
+
+		byIndex, err := archive.GetEntryByIndex(entry.Index())
+		if err != nil {
+			t.Fatalf("Failed to get entry at index %d: %v", entry.Index(), err)
+		}
+		if byIndex.Path() != entry.Path() {
+			t.Errorf("Expected index %d to map to %q, got %q", entry.Index(), entry.Path(), byIndex.Path())
+		}
+		byIndex.Close()
+
+		if !entry.IsRedirect() {
+			if _, err := entry.RedirectEntry(); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Expected ErrNotFound for RedirectEntry on %q, got %v", entry.Path(), err)
+			}
+			entry.Close()
+			continue
+		}
+
+		redirects++
+		chain, err := entry.RedirectChain()
+		if err != nil {
+			t.Fatalf("Failed to follow redirect chain from %q: %v", entry.Path(), err)
+		}
+		if len(chain) == 0 || chain[len(chain)-1].IsRedirect() {
+			t.Errorf("Expected redirect chain from %q to end on a non-redirect entry", entry.Path())
+		}
+		for _, e := range chain {
+			e.Close()
+		}
+		entry.Close()
+	}
+	t.Logf("Checked redirect chains of %d redirects", redirects)
 }
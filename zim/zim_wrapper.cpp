@@ -7,17 +7,149 @@
 #include <zim/suggestion.h>
 #include <zim/writer/creator.h>
 #include <zim/writer/item.h>
+#include <zim/error.h>
+// Illustrations of any shape and scale need zim/illustration.h, missing from
+// older libzim releases. Only square, unscaled ones are available without it.
+#if defined(__has_include)
+#if __has_include(<zim/illustration.h>)
+#include <zim/illustration.h>
+#define ZIM_HAS_ILLUSTRATION_INFO 1
+#endif
+#endif
 #include <cstring>
 #include <cstdlib>
+#include <map>
+#include <ios>
+#include <system_error>
+#include <vector>
+#include <unicode/unorm2.h>
+#include <unicode/ustring.h>
 
 using namespace zim;
 
+// Helper for strings
+static char* copy_string(const std::string& str) {
+    char* copy = (char*)malloc(str.length() + 1);
+    if (copy) strcpy(copy, str.c_str());
+    return copy;
+}
+
+static void set_error(zim_error_t* err, int code, const char* message) {
+    if (!err) return;
+    err->code = code;
+    err->message = copy_string(message);
+}
+
+// Must be called from inside a catch block: rethrows the in-flight exception
+// to classify it. Unclassified exceptions are reported with the fallback code.
+static void capture_error(zim_error_t* err, int fallback = ZIM_ERR_UNKNOWN) {
+    try {
+        throw;
+    } catch (const EntryNotFound& e) {
+        set_error(err, ZIM_ERR_NOT_FOUND, e.what());
+    } catch (const std::out_of_range& e) {
+        set_error(err, ZIM_ERR_NOT_FOUND, e.what());
+    } catch (const ZimFileFormatError& e) {
+        set_error(err, ZIM_ERR_INVALID_ARCHIVE, e.what());
+    } catch (const InvalidEntry& e) {
+        set_error(err, ZIM_ERR_DUPLICATE_PATH, e.what());
+    } catch (const CreatorStateError& e) {
+        set_error(err, ZIM_ERR_CREATOR_STATE, e.what());
+    } catch (const std::ios_base::failure& e) {
+        set_error(err, ZIM_ERR_IO, e.what());
+    } catch (const std::system_error& e) {
+        set_error(err, ZIM_ERR_IO, e.what());
+    } catch (const std::exception& e) {
+        set_error(err, fallback, e.what());
+    } catch (...) {
+        set_error(err, fallback, "unknown exception");
+    }
+}
+
+// Type-erased walk over an Archive::EntryRange, whatever its order
+struct EntryCursor {
+    virtual ~EntryCursor() {}
+    virtual bool done() const = 0;
+    virtual Entry next() = 0;
+};
+
+template<EntryOrder order>
+struct RangeCursor : EntryCursor {
+    Archive::EntryRange<order> range;
+    Archive::iterator<order> it;
+    Archive::iterator<order> end;
+
+    explicit RangeCursor(const Archive::EntryRange<order>& r)
+        : range(r), it(range.begin()), end(range.end()) {}
+
+    bool done() const override { return it == end; }
+
+    Entry next() override {
+        Entry entry = *it;
+        ++it;
+        return entry;
+    }
+};
+
+template<EntryOrder order>
+static EntryCursor* new_cursor(const Archive::EntryRange<order>& range) {
+    return new RangeCursor<order>(range);
+}
+
+// Describes the entry behind a search or suggestion result. Filled while the
+// page is read, so rendering results needs no further lookup.
+static bool fill_result_entry(const Entry& entry, zim_result_entry_t* out) {
+    Item item = entry.getItem(true);
+    std::string mimetype = item.getMimetype();
+    uint64_t size = item.getSize();
+
+    out->index = entry.getIndex();
+    out->mimetype = copy_string(mimetype);
+    out->size = size;
+    return true;
+}
+
 extern "C" {
 
-zim_archive_t zim_archive_new(const char* path) {
+zim_archive_t zim_archive_new(const char* path, zim_error_t* err) {
     try {
         return new Archive(path);
     } catch(...) {
+        capture_error(err, ZIM_ERR_INVALID_ARCHIVE);
+        return nullptr;
+    }
+}
+
+zim_archive_t zim_archive_new_with_config(const char* path, int preload_xapian_db, int preload_dirent_ranges, zim_error_t* err) {
+    try {
+        OpenConfig config;
+        if (preload_xapian_db >= 0) {
+            config.preloadXapianDb(preload_xapian_db != 0);
+        }
+        if (preload_dirent_ranges >= 0) {
+            config.preloadDirentRanges(preload_dirent_ranges);
+        }
+        return new Archive(path, config);
+    } catch(...) {
+        capture_error(err, ZIM_ERR_INVALID_ARCHIVE);
+        return nullptr;
+    }
+}
+
+zim_archive_t zim_archive_new_from_fd(int fd, zim_error_t* err) {
+    try {
+        return new Archive(fd);
+    } catch(...) {
+        capture_error(err, ZIM_ERR_INVALID_ARCHIVE);
+        return nullptr;
+    }
+}
+
+zim_archive_t zim_archive_new_from_fd_range(int fd, uint64_t offset, uint64_t size, zim_error_t* err) {
+    try {
+        return new Archive(FdInput(fd, offset, size));
+    } catch(...) {
+        capture_error(err, ZIM_ERR_INVALID_ARCHIVE);
         return nullptr;
     }
 }
@@ -33,39 +165,85 @@ uint64_t zim_archive_get_entry_count(zim_archive_t archive) {
 
 bool zim_archive_has_entry_by_path(zim_archive_t archive, const char* path) {
     if (!archive) return false;
-    return static_cast<Archive*>(archive)->hasEntryByPath(path);
+    try { return static_cast<Archive*>(archive)->hasEntryByPath(path); }
+    catch(...) { return false; }
+}
+
+bool zim_archive_has_entry_by_title(zim_archive_t archive, const char* title) {
+    if (!archive) return false;
+    try { return static_cast<Archive*>(archive)->hasEntryByTitle(title); }
+    catch(...) { return false; }
 }
 
-zim_entry_t zim_archive_get_entry_by_path(zim_archive_t archive, const char* path) {
+zim_entry_t zim_archive_get_entry_by_path(zim_archive_t archive, const char* path, zim_error_t* err) {
     try {
         Archive* arch = static_cast<Archive*>(archive);
         Entry entry = arch->getEntryByPath(path);
         return new Entry(entry);
     } catch(...) {
+        capture_error(err);
         return nullptr;
     }
 }
 
-zim_entry_t zim_archive_get_main_entry(zim_archive_t archive) {
+zim_entry_t zim_archive_get_main_entry(zim_archive_t archive, zim_error_t* err) {
     try {
         Archive* arch = static_cast<Archive*>(archive);
         if (!arch->hasMainEntry()) {
+            set_error(err, ZIM_ERR_NOT_FOUND, "archive has no main entry");
             return nullptr;
         }
         Entry entry = arch->getMainEntry();
         return new Entry(entry);
     } catch(...) {
+        capture_error(err);
         return nullptr;
     }
 }
 
-zim_entry_t zim_archive_get_entry_by_index(zim_archive_t archive, uint32_t idx) {
+zim_entry_t zim_archive_get_entry_by_index(zim_archive_t archive, uint32_t idx, zim_error_t* err) {
     try {
         Archive* arch = static_cast<Archive*>(archive);
         // getEntryByPath(idx) gets the idx'th entry sorted by path
         Entry entry = arch->getEntryByPath(idx); 
         return new Entry(entry);
     } catch(...) {
+        capture_error(err);
+        return nullptr;
+    }
+}
+
+zim_entry_t zim_archive_get_entry_by_title(zim_archive_t archive, const char* title, zim_error_t* err) {
+    try {
+        Archive* arch = static_cast<Archive*>(archive);
+        Entry entry = arch->getEntryByTitle(title);
+        return new Entry(entry);
+    } catch(...) {
+        capture_error(err);
+        return nullptr;
+    }
+}
+
+zim_entry_t zim_archive_get_entry_by_title_index(zim_archive_t archive, uint32_t idx, zim_error_t* err) {
+    try {
+        Archive* arch = static_cast<Archive*>(archive);
+        // getEntryByTitle(idx) gets the idx'th entry sorted by title
+        Entry entry = arch->getEntryByTitle(idx);
+        return new Entry(entry);
+    } catch(...) {
+        capture_error(err);
+        return nullptr;
+    }
+}
+
+zim_entry_t zim_archive_get_random_entry(zim_archive_t archive, zim_error_t* err) {
+    try {
+        Archive* arch = static_cast<Archive*>(archive);
+        // getRandomEntry only picks front articles
+        Entry entry = arch->getRandomEntry();
+        return new Entry(entry);
+    } catch(...) {
+        capture_error(err);
         return nullptr;
     }
 }
@@ -75,6 +253,437 @@ bool zim_archive_has_fulltext_index(zim_archive_t archive) {
     return static_cast<Archive*>(archive)->hasFulltextIndex();
 }
 
+char* zim_archive_get_uuid(zim_archive_t archive) {
+    if (!archive) return nullptr;
+    try { return copy_string(static_cast<Archive*>(archive)->getUuid()); }
+    catch(...) { return nullptr; }
+}
+
+uint64_t zim_archive_get_filesize(zim_archive_t archive) {
+    if (!archive) return 0;
+    try { return static_cast<Archive*>(archive)->getFilesize(); }
+    catch(...) { return 0; }
+}
+
+bool zim_archive_is_multipart(zim_archive_t archive) {
+    if (!archive) return false;
+    try { return static_cast<Archive*>(archive)->isMultiPart(); }
+    catch(...) { return false; }
+}
+
+bool zim_archive_has_new_namespace_scheme(zim_archive_t archive) {
+    if (!archive) return false;
+    try { return static_cast<Archive*>(archive)->hasNewNamespaceScheme(); }
+    catch(...) { return false; }
+}
+
+bool zim_archive_has_checksum(zim_archive_t archive) {
+    if (!archive) return false;
+    try { return static_cast<Archive*>(archive)->hasChecksum(); }
+    catch(...) { return false; }
+}
+
+char* zim_archive_get_checksum(zim_archive_t archive, zim_error_t* err) {
+    try {
+        Archive* arch = static_cast<Archive*>(archive);
+        if (!arch->hasChecksum()) {
+            set_error(err, ZIM_ERR_NOT_FOUND, "archive has no checksum");
+            return nullptr;
+        }
+        return copy_string(arch->getChecksum());
+    } catch(...) {
+        capture_error(err);
+        return nullptr;
+    }
+}
+
+bool zim_archive_verify_checksum(zim_archive_t archive, zim_error_t* err) {
+    try {
+        return static_cast<Archive*>(archive)->check();
+    } catch(...) {
+        capture_error(err, ZIM_ERR_INVALID_ARCHIVE);
+        return false;
+    }
+}
+
+bool zim_archive_check_integrity(zim_archive_t archive, zim_integrity_check_t check, zim_error_t* err) {
+    try {
+        IntegrityCheck type;
+        switch (check) {
+            case ZIM_CHECK_CHECKSUM: type = IntegrityCheck::CHECKSUM; break;
+            case ZIM_CHECK_DIRENT_PTRS: type = IntegrityCheck::DIRENT_PTRS; break;
+            case ZIM_CHECK_DIRENT_ORDER: type = IntegrityCheck::DIRENT_ORDER; break;
+            case ZIM_CHECK_TITLE_INDEX: type = IntegrityCheck::TITLE_INDEX; break;
+            case ZIM_CHECK_CLUSTER_PTRS: type = IntegrityCheck::CLUSTER_PTRS; break;
+            case ZIM_CHECK_CLUSTERS_OFFSETS: type = IntegrityCheck::CLUSTERS_OFFSETS; break;
+            case ZIM_CHECK_DIRENT_MIMETYPES: type = IntegrityCheck::DIRENT_MIMETYPES; break;
+            default:
+                set_error(err, ZIM_ERR_UNKNOWN, "unknown integrity check");
+                return false;
+        }
+        return static_cast<Archive*>(archive)->checkIntegrity(type);
+    } catch(...) {
+        capture_error(err, ZIM_ERR_INVALID_ARCHIVE);
+        return false;
+    }
+}
+
+// Follows every redirect chain and fails if one of them never reaches an item
+bool zim_archive_check_redirect_loops(zim_archive_t archive, zim_error_t* err) {
+    try {
+        Archive* arch = static_cast<Archive*>(archive);
+        entry_index_type count = arch->getEntryCount();
+        for (entry_index_type i = 0; i < count; i++) {
+            Entry entry = arch->getEntryByPath(i);
+            entry_index_type hops = 0;
+            while (entry.isRedirect()) {
+                if (++hops > count) {
+                    set_error(err, ZIM_ERR_INVALID_ARCHIVE, ("redirect loop at " + arch->getEntryByPath(i).getPath()).c_str());
+                    return false;
+                }
+                entry = entry.getRedirectEntry();
+            }
+        }
+        return true;
+    } catch(...) {
+        capture_error(err, ZIM_ERR_INVALID_ARCHIVE);
+        return false;
+    }
+}
+
+uint64_t zim_get_cluster_cache_max_size() {
+    return getClusterCacheMaxSize();
+}
+
+uint64_t zim_get_cluster_cache_current_size() {
+    return getClusterCacheCurrentSize();
+}
+
+bool zim_set_cluster_cache_max_size(uint64_t size, zim_error_t* err) {
+    try {
+        setClusterCacheMaxSize(size);
+        return true;
+    } catch(...) {
+        capture_error(err);
+        return false;
+    }
+}
+
+uint64_t zim_archive_get_dirent_cache_max_size(zim_archive_t archive) {
+    if (!archive) return 0;
+    return static_cast<Archive*>(archive)->getDirentCacheMaxSize();
+}
+
+uint64_t zim_archive_get_dirent_cache_current_size(zim_archive_t archive) {
+    if (!archive) return 0;
+    return static_cast<Archive*>(archive)->getDirentCacheCurrentSize();
+}
+
+bool zim_archive_set_dirent_cache_max_size(zim_archive_t archive, uint64_t size, zim_error_t* err) {
+    try {
+        static_cast<Archive*>(archive)->setDirentCacheMaxSize(size);
+        return true;
+    } catch(...) {
+        capture_error(err);
+        return false;
+    }
+}
+
+uint64_t zim_archive_get_dirent_lookup_cache_max_size(zim_archive_t archive) {
+    if (!archive) return 0;
+    return static_cast<Archive*>(archive)->getDirentLookupCacheMaxSize();
+}
+
+bool zim_archive_set_dirent_lookup_cache_max_size(zim_archive_t archive, uint64_t size, zim_error_t* err) {
+    try {
+        static_cast<Archive*>(archive)->setDirentLookupCacheMaxSize(size);
+        return true;
+    } catch(...) {
+        capture_error(err);
+        return false;
+    }
+}
+
+bool zim_archive_get_stats(zim_archive_t archive, zim_archive_stats_t* stats, zim_error_t* err) {
+    memset(stats, 0, sizeof(*stats));
+    try {
+        Archive* arch = static_cast<Archive*>(archive);
+        stats->all_entry_count = arch->getAllEntryCount();
+        stats->entry_count = arch->getEntryCount();
+        stats->article_count = arch->getArticleCount();
+        stats->media_count = arch->getMediaCount();
+
+        // Cluster order reads each cluster once
+        std::map<std::string, std::pair<uint64_t, uint64_t> > per_mimetype;
+        for (const Entry& entry : arch->iterEfficient()) {
+            if (entry.isRedirect()) {
+                stats->redirect_count++;
+                continue;
+            }
+            Item item = entry.getItem();
+            std::pair<uint64_t, uint64_t>& acc = per_mimetype[item.getMimetype()];
+            acc.first++;
+            acc.second += item.getSize();
+        }
+
+        size_t n = per_mimetype.size();
+        stats->mimetypes = (char**)calloc(n + 1, sizeof(char*));
+        stats->mimetype_items = (uint64_t*)calloc(n + 1, sizeof(uint64_t));
+        stats->mimetype_sizes = (uint64_t*)calloc(n + 1, sizeof(uint64_t));
+        if (!stats->mimetypes || !stats->mimetype_items || !stats->mimetype_sizes) {
+            zim_archive_stats_free(stats);
+            set_error(err, ZIM_ERR_UNKNOWN, "out of memory");
+            return false;
+        }
+
+        int i = 0;
+        for (const auto& kv : per_mimetype) {
+            stats->mimetypes[i] = copy_string(kv.first);
+            stats->mimetype_items[i] = kv.second.first;
+            stats->mimetype_sizes[i] = kv.second.second;
+            i++;
+        }
+        stats->mimetype_count = i;
+        return true;
+    } catch(...) {
+        zim_archive_stats_free(stats);
+        capture_error(err);
+        return false;
+    }
+}
+
+void zim_archive_stats_free(zim_archive_stats_t* stats) {
+    if (!stats) return;
+    zim_string_array_free(stats->mimetypes, stats->mimetype_count);
+    free(stats->mimetype_items);
+    free(stats->mimetype_sizes);
+    stats->mimetypes = nullptr;
+    stats->mimetype_items = nullptr;
+    stats->mimetype_sizes = nullptr;
+    stats->mimetype_count = 0;
+}
+
+char* zim_archive_get_metadata(zim_archive_t archive, const char* name, uint64_t* size, zim_error_t* err) {
+    try {
+        std::string value = static_cast<Archive*>(archive)->getMetadata(name);
+        char* buf = (char*)malloc(value.size() + 1);
+        if (buf) {
+            memcpy(buf, value.data(), value.size());
+            buf[value.size()] = '\0';
+        }
+        *size = value.size();
+        return buf;
+    } catch(...) {
+        capture_error(err);
+        *size = 0;
+        return nullptr;
+    }
+}
+
+char** zim_archive_get_metadata_keys(zim_archive_t archive, int* count, zim_error_t* err) {
+    try {
+        std::vector<std::string> keys = static_cast<Archive*>(archive)->getMetadataKeys();
+        *count = keys.size();
+        char** array = (char**)malloc(sizeof(char*) * (keys.size() + 1));
+        if (!array) {
+            *count = 0;
+            return nullptr;
+        }
+        for (size_t i = 0; i < keys.size(); i++) {
+            array[i] = copy_string(keys[i]);
+        }
+        array[keys.size()] = nullptr;
+        return array;
+    } catch(...) {
+        capture_error(err);
+        *count = 0;
+        return nullptr;
+    }
+}
+
+void zim_string_array_free(char** array, int count) {
+    if (!array) return;
+    for (int i = 0; i < count; i++) {
+        free(array[i]);
+    }
+    free(array);
+}
+
+// Uses the ICU C API, which libzim already links against
+char* zim_string_normalize(const char* str) {
+    if (!str) return nullptr;
+    try {
+        UErrorCode status = U_ZERO_ERROR;
+        const UNormalizer2* nfc = unorm2_getNFCInstance(&status);
+        if (U_FAILURE(status)) return nullptr;
+
+        int32_t srcLen = 0;
+        u_strFromUTF8(nullptr, 0, &srcLen, str, -1, &status);
+        if (status != U_BUFFER_OVERFLOW_ERROR && U_FAILURE(status)) return nullptr;
+        status = U_ZERO_ERROR;
+        std::vector<UChar> src(srcLen + 1);
+        u_strFromUTF8(src.data(), srcLen + 1, nullptr, str, -1, &status);
+        if (U_FAILURE(status)) return nullptr;
+
+        int32_t dstLen = unorm2_normalize(nfc, src.data(), srcLen, nullptr, 0, &status);
+        if (status != U_BUFFER_OVERFLOW_ERROR && U_FAILURE(status)) return nullptr;
+        status = U_ZERO_ERROR;
+        std::vector<UChar> dst(dstLen + 1);
+        unorm2_normalize(nfc, src.data(), srcLen, dst.data(), dstLen + 1, &status);
+        if (U_FAILURE(status)) return nullptr;
+
+        int32_t outLen = 0;
+        u_strToUTF8(nullptr, 0, &outLen, dst.data(), dstLen, &status);
+        if (status != U_BUFFER_OVERFLOW_ERROR && U_FAILURE(status)) return nullptr;
+        status = U_ZERO_ERROR;
+        std::string out(outLen, '\0');
+        u_strToUTF8(&out[0], outLen, nullptr, dst.data(), dstLen, &status);
+        if (U_FAILURE(status)) return nullptr;
+        return copy_string(out);
+    } catch(...) {
+        return nullptr;
+    }
+}
+
+unsigned int* zim_archive_get_illustration_sizes(zim_archive_t archive, int* count, zim_error_t* err) {
+    try {
+        std::set<unsigned int> sizes = static_cast<Archive*>(archive)->getIllustrationSizes();
+        *count = sizes.size();
+        unsigned int* array = (unsigned int*)malloc(sizeof(unsigned int) * (sizes.size() + 1));
+        if (!array) {
+            *count = 0;
+            return nullptr;
+        }
+        int i = 0;
+        for (unsigned int size : sizes) {
+            array[i++] = size;
+        }
+        return array;
+    } catch(...) {
+        capture_error(err);
+        *count = 0;
+        return nullptr;
+    }
+}
+
+zim_item_t zim_archive_get_illustration_item(zim_archive_t archive, unsigned int size, zim_error_t* err) {
+    try {
+        Archive* arch = static_cast<Archive*>(archive);
+        if (!arch->hasIllustration(size)) {
+            set_error(err, ZIM_ERR_NOT_FOUND, "archive has no illustration of this size");
+            return nullptr;
+        }
+        return new Item(arch->getIllustrationItem(size));
+    } catch(...) {
+        capture_error(err);
+        return nullptr;
+    }
+}
+
+zim_illustration_info_t* zim_archive_get_illustration_infos(zim_archive_t archive, int* count, zim_error_t* err) {
+    try {
+#ifdef ZIM_HAS_ILLUSTRATION_INFO
+        IllustrationInfos infos = static_cast<Archive*>(archive)->getIllustrationInfos();
+        *count = infos.size();
+        zim_illustration_info_t* array = (zim_illustration_info_t*)malloc(sizeof(zim_illustration_info_t) * (infos.size() + 1));
+        if (!array) {
+            *count = 0;
+            return nullptr;
+        }
+        for (size_t i = 0; i < infos.size(); i++) {
+            array[i].width = infos[i].width;
+            array[i].height = infos[i].height;
+            array[i].scale = infos[i].scale;
+        }
+        return array;
+#else
+        std::set<unsigned int> sizes = static_cast<Archive*>(archive)->getIllustrationSizes();
+        *count = sizes.size();
+        zim_illustration_info_t* array = (zim_illustration_info_t*)malloc(sizeof(zim_illustration_info_t) * (sizes.size() + 1));
+        if (!array) {
+            *count = 0;
+            return nullptr;
+        }
+        int i = 0;
+        for (unsigned int size : sizes) {
+            array[i].width = size;
+            array[i].height = size;
+            array[i].scale = 1;
+            i++;
+        }
+        return array;
+#endif
+    } catch(...) {
+        capture_error(err);
+        *count = 0;
+        return nullptr;
+    }
+}
+
+zim_item_t zim_archive_get_illustration_item_by_info(zim_archive_t archive, zim_illustration_info_t info, zim_error_t* err) {
+    try {
+#ifdef ZIM_HAS_ILLUSTRATION_INFO
+        IllustrationInfo ii;
+        ii.width = info.width;
+        ii.height = info.height;
+        ii.scale = info.scale;
+        return new Item(static_cast<Archive*>(archive)->getIllustrationItem(ii));
+#else
+        if (info.width != info.height || info.scale != 1) {
+            set_error(err, ZIM_ERR_NOT_FOUND, "illustrations other than square and unscaled need a newer libzim");
+            return nullptr;
+        }
+        return zim_archive_get_illustration_item(archive, info.width, err);
+#endif
+    } catch(...) {
+        capture_error(err);
+        return nullptr;
+    }
+}
+
+zim_entry_cursor_t zim_archive_iter_by_path(zim_archive_t archive, zim_error_t* err) {
+    try { return new_cursor(static_cast<Archive*>(archive)->iterByPath()); }
+    catch(...) { capture_error(err); return nullptr; }
+}
+
+zim_entry_cursor_t zim_archive_iter_by_title(zim_archive_t archive, zim_error_t* err) {
+    try { return new_cursor(static_cast<Archive*>(archive)->iterByTitle()); }
+    catch(...) { capture_error(err); return nullptr; }
+}
+
+zim_entry_cursor_t zim_archive_iter_efficient(zim_archive_t archive, zim_error_t* err) {
+    try { return new_cursor(static_cast<Archive*>(archive)->iterEfficient()); }
+    catch(...) { capture_error(err); return nullptr; }
+}
+
+zim_entry_cursor_t zim_archive_find_by_path(zim_archive_t archive, const char* prefix, zim_error_t* err) {
+    try { return new_cursor(static_cast<Archive*>(archive)->findByPath(prefix)); }
+    catch(...) { capture_error(err); return nullptr; }
+}
+
+zim_entry_cursor_t zim_archive_find_by_title(zim_archive_t archive, const char* prefix, zim_error_t* err) {
+    try { return new_cursor(static_cast<Archive*>(archive)->findByTitle(prefix)); }
+    catch(...) { capture_error(err); return nullptr; }
+}
+
+int zim_entry_cursor_next_batch(zim_entry_cursor_t cursor, zim_entry_t* entries, int max, zim_error_t* err) {
+    EntryCursor* c = static_cast<EntryCursor*>(cursor);
+    int n = 0;
+    try {
+        while (n < max && !c->done()) {
+            entries[n] = new Entry(c->next());
+            n++;
+        }
+    } catch(...) {
+        capture_error(err);
+    }
+    return n;
+}
+
+void zim_entry_cursor_free(zim_entry_cursor_t cursor) {
+    delete static_cast<EntryCursor*>(cursor);
+}
+
 void zim_entry_free(zim_entry_t entry) {
     delete static_cast<Entry*>(entry);
 }
@@ -84,12 +693,44 @@ bool zim_entry_is_redirect(zim_entry_t entry) {
     return static_cast<Entry*>(entry)->isRedirect();
 }
 
-zim_item_t zim_entry_get_item(zim_entry_t entry, bool follow) {
+char* zim_entry_get_path(zim_entry_t entry) {
+    if (!entry) return nullptr;
+    try { return copy_string(static_cast<Entry*>(entry)->getPath()); }
+    catch(...) { return nullptr; }
+}
+
+char* zim_entry_get_title(zim_entry_t entry) {
+    if (!entry) return nullptr;
+    try { return copy_string(static_cast<Entry*>(entry)->getTitle()); }
+    catch(...) { return nullptr; }
+}
+
+uint32_t zim_entry_get_index(zim_entry_t entry) {
+    if (!entry) return 0;
+    return static_cast<Entry*>(entry)->getIndex();
+}
+
+zim_entry_t zim_entry_get_redirect_entry(zim_entry_t entry, zim_error_t* err) {
+    try {
+        Entry* e = static_cast<Entry*>(entry);
+        if (!e->isRedirect()) {
+            set_error(err, ZIM_ERR_NOT_FOUND, "entry is not a redirect");
+            return nullptr;
+        }
+        return new Entry(e->getRedirectEntry());
+    } catch(...) {
+        capture_error(err);
+        return nullptr;
+    }
+}
+
+zim_item_t zim_entry_get_item(zim_entry_t entry, bool follow, zim_error_t* err) {
     try {
         Entry* e = static_cast<Entry*>(entry);
         Item item = e->getItem(follow);
         return new Item(item);
     } catch(...) {
+        capture_error(err);
         return nullptr;
     }
 }
@@ -98,13 +739,6 @@ void zim_item_free(zim_item_t item) {
     delete static_cast<Item*>(item);
 }
 
-// Helper for strings
-static char* copy_string(const std::string& str) {
-    char* copy = (char*)malloc(str.length() + 1);
-    if (copy) strcpy(copy, str.c_str());
-    return copy;
-}
-
 char* zim_item_get_path(zim_item_t item) {
     try { return copy_string(static_cast<Item*>(item)->getPath()); } 
     catch(...) { return nullptr; }
@@ -125,43 +759,117 @@ uint64_t zim_item_get_size(zim_item_t item) {
     catch(...) { return 0; }
 }
 
-char* zim_item_get_data(zim_item_t item, uint64_t* size) {
+zim_blob_t zim_item_get_blob(zim_item_t item, zim_error_t* err) {
     try {
-        Blob b = static_cast<Item*>(item)->getData();
-        *size = b.size();
-        char* buf = (char*)malloc(b.size());
-        if (buf) {
-            memcpy(buf, b.data(), b.size());
-        }
-        return buf;
+        // The Blob shares the cluster buffer, no content is copied
+        return new Blob(static_cast<Item*>(item)->getData());
     } catch(...) {
-        *size = 0;
+        capture_error(err, ZIM_ERR_IO);
         return nullptr;
     }
 }
 
+char* zim_item_get_direct_access(zim_item_t item, uint64_t* offset) {
+    try {
+        ItemDataDirectAccessInfo info = static_cast<Item*>(item)->getDirectAccessInformation();
+        if (!info.isValid()) return nullptr;
+        *offset = info.offset;
+        return copy_string(info.filename);
+    } catch(...) {
+        return nullptr;
+    }
+}
+
+uint64_t zim_item_read_at(zim_item_t item, uint64_t offset, char* buf, uint64_t len, zim_error_t* err) {
+    try {
+        Item* it = static_cast<Item*>(item);
+        uint64_t size = it->getSize();
+        if (offset >= size) return 0;
+        if (len > size - offset) len = size - offset;
+        Blob b = it->getData(offset, len);
+        memcpy(buf, b.data(), b.size());
+        return b.size();
+    } catch(...) {
+        capture_error(err, ZIM_ERR_IO);
+        return 0;
+    }
+}
+
+void zim_blob_free(zim_blob_t blob) {
+    delete static_cast<Blob*>(blob);
+}
+
+const char* zim_blob_data(zim_blob_t blob) {
+    if (!blob) return nullptr;
+    return static_cast<Blob*>(blob)->data();
+}
+
+uint64_t zim_blob_size(zim_blob_t blob) {
+    if (!blob) return 0;
+    return static_cast<Blob*>(blob)->size();
+}
 
 // --- Search API ---
 
-zim_query_t zim_query_new(const char* query_str) {
-    try { return new Query(query_str); } catch(...) { return nullptr; }
+zim_query_t zim_query_new(const char* query_str, zim_error_t* err) {
+    try { return new Query(query_str); } catch(...) { capture_error(err); return nullptr; }
 }
 void zim_query_free(zim_query_t query) { delete static_cast<Query*>(query); }
+bool zim_query_set_georange(zim_query_t query, float latitude, float longitude, float distance, zim_error_t* err) {
+    try {
+        static_cast<Query*>(query)->setGeorange(latitude, longitude, distance);
+        return true;
+    } catch(...) {
+        capture_error(err);
+        return false;
+    }
+}
 
-zim_searcher_t zim_searcher_new(zim_archive_t archive) {
+zim_searcher_t zim_searcher_new(zim_archive_t archive, zim_error_t* err) {
     try {
         Archive* arch = static_cast<Archive*>(archive);
+        if (!arch->hasFulltextIndex()) {
+            set_error(err, ZIM_ERR_NO_FULLTEXT_INDEX, "archive has no fulltext index");
+            return nullptr;
+        }
         return new Searcher(*arch);
-    } catch(...) { return nullptr; }
+    } catch(...) { capture_error(err); return nullptr; }
+}
+
+zim_searcher_t zim_searcher_new_multi(zim_archive_t* archives, int count, zim_error_t* err) {
+    try {
+        std::vector<Archive> list;
+        for (int i = 0; i < count; i++) {
+            Archive* arch = static_cast<Archive*>(archives[i]);
+            if (!arch->hasFulltextIndex()) {
+                set_error(err, ZIM_ERR_NO_FULLTEXT_INDEX, ("archive " + std::to_string(i) + " has no fulltext index").c_str());
+                return nullptr;
+            }
+            list.push_back(*arch);
+        }
+        return new Searcher(list);
+    } catch(...) { capture_error(err); return nullptr; }
+}
+
+bool zim_searcher_add_archive(zim_searcher_t searcher, zim_archive_t archive, zim_error_t* err) {
+    try {
+        Archive* arch = static_cast<Archive*>(archive);
+        if (!arch->hasFulltextIndex()) {
+            set_error(err, ZIM_ERR_NO_FULLTEXT_INDEX, "archive has no fulltext index");
+            return false;
+        }
+        static_cast<Searcher*>(searcher)->addArchive(*arch);
+        return true;
+    } catch(...) { capture_error(err); return false; }
 }
 void zim_searcher_free(zim_searcher_t searcher) { delete static_cast<Searcher*>(searcher); }
 
-zim_search_t zim_searcher_search(zim_searcher_t searcher, zim_query_t query) {
+zim_search_t zim_searcher_search(zim_searcher_t searcher, zim_query_t query, zim_error_t* err) {
     try {
         Searcher* s = static_cast<Searcher*>(searcher);
         Query* q = static_cast<Query*>(query);
         return new Search(s->search(*q));
-    } catch(...) { return nullptr; }
+    } catch(...) { capture_error(err); return nullptr; }
 }
 void zim_search_free(zim_search_t search) { delete static_cast<Search*>(search); }
 
@@ -170,11 +878,11 @@ int zim_search_get_estimated_matches(zim_search_t search) {
     return static_cast<Search*>(search)->getEstimatedMatches();
 }
 
-zim_search_result_set_t zim_search_get_results(zim_search_t search, int start, int max_results) {
+zim_search_result_set_t zim_search_get_results(zim_search_t search, int start, int max_results, zim_error_t* err) {
     try {
         Search* s = static_cast<Search*>(search);
         return new SearchResultSet(s->getResults(start, max_results));
-    } catch(...) { return nullptr; }
+    } catch(...) { capture_error(err); return nullptr; }
 }
 void zim_search_result_set_free(zim_search_result_set_t set) { delete static_cast<SearchResultSet*>(set); }
 
@@ -223,13 +931,28 @@ int zim_search_iterator_get_word_count(zim_search_iterator_t it) {
     try { return static_cast<SearchIterator*>(it)->getWordCount(); } catch(...) { return 0; }
 }
 
+int zim_search_iterator_get_file_index(zim_search_iterator_t it) {
+    if (!it) return -1;
+    try { return static_cast<SearchIterator*>(it)->getFileIndex(); } catch(...) { return -1; }
+}
+
+char* zim_search_iterator_get_zim_id(zim_search_iterator_t it) {
+    if (!it) return nullptr;
+    try { return copy_string(static_cast<SearchIterator*>(it)->getZimId()); } catch(...) { return nullptr; }
+}
+
+bool zim_search_iterator_get_entry(zim_search_iterator_t it, zim_result_entry_t* out) {
+    if (!it || !out) return false;
+    try { return fill_result_entry(**static_cast<SearchIterator*>(it), out); } catch(...) { return false; }
+}
+
 // --- Suggestion API ---
 
-zim_suggestion_searcher_t zim_suggestion_searcher_new(zim_archive_t archive) {
+zim_suggestion_searcher_t zim_suggestion_searcher_new(zim_archive_t archive, zim_error_t* err) {
     try {
         Archive* arch = static_cast<Archive*>(archive);
         return new SuggestionSearcher(*arch);
-    } catch(...) { return nullptr; }
+    } catch(...) { capture_error(err); return nullptr; }
 }
 void zim_suggestion_searcher_free(zim_suggestion_searcher_t searcher) { 
     delete static_cast<SuggestionSearcher*>(searcher); 
@@ -241,11 +964,11 @@ void zim_suggestion_searcher_set_verbose(zim_suggestion_searcher_t searcher, boo
     }
 }
 
-zim_suggestion_search_t zim_suggestion_searcher_suggest(zim_suggestion_searcher_t searcher, const char* query) {
+zim_suggestion_search_t zim_suggestion_searcher_suggest(zim_suggestion_searcher_t searcher, const char* query, zim_error_t* err) {
     try {
         SuggestionSearcher* s = static_cast<SuggestionSearcher*>(searcher);
         return new SuggestionSearch(s->suggest(query));
-    } catch(...) { return nullptr; }
+    } catch(...) { capture_error(err); return nullptr; }
 }
 void zim_suggestion_search_free(zim_suggestion_search_t search) { 
     delete static_cast<SuggestionSearch*>(search); 
@@ -256,11 +979,11 @@ int zim_suggestion_search_get_estimated_matches(zim_suggestion_search_t search)
     return static_cast<SuggestionSearch*>(search)->getEstimatedMatches();
 }
 
-zim_suggestion_result_set_t zim_suggestion_search_get_results(zim_suggestion_search_t search, int start, int max_results) {
+zim_suggestion_result_set_t zim_suggestion_search_get_results(zim_suggestion_search_t search, int start, int max_results, zim_error_t* err) {
     try {
         SuggestionSearch* s = static_cast<SuggestionSearch*>(search);
         return new SuggestionResultSet(s->getResults(start, max_results));
-    } catch(...) { return nullptr; }
+    } catch(...) { capture_error(err); return nullptr; }
 }
 void zim_suggestion_result_set_free(zim_suggestion_result_set_t set) { 
     delete static_cast<SuggestionResultSet*>(set); 
@@ -324,10 +1047,15 @@ bool zim_suggestion_iterator_has_snippet(zim_suggestion_iterator_t it) {
     } catch(...) { return false; }
 }
 
+bool zim_suggestion_iterator_get_entry(zim_suggestion_iterator_t it, zim_result_entry_t* out) {
+    if (!it || !out) return false;
+    try { return fill_result_entry(static_cast<SuggestionIterator*>(it)->getEntry(), out); } catch(...) { return false; }
+}
+
 // --- Writer API ---
 
-zim_creator_t zim_creator_new() {
-    try { return new zim::writer::Creator(); } catch(...) { return nullptr; }
+zim_creator_t zim_creator_new(zim_error_t* err) {
+    try { return new zim::writer::Creator(); } catch(...) { capture_error(err); return nullptr; }
 }
 
 void zim_creator_free(zim_creator_t creator) {
@@ -342,51 +1070,58 @@ void zim_creator_config_compression(zim_creator_t creator, int compression) {
     if (creator) static_cast<zim::writer::Creator*>(creator)->configCompression(static_cast<zim::Compression>(compression));
 }
 
-bool zim_creator_start_zim_creation(zim_creator_t creator, const char* filepath) {
+void zim_creator_config_indexing(zim_creator_t creator, bool indexing, const char* language) {
+    if (!creator) return;
+    try {
+        static_cast<zim::writer::Creator*>(creator)->configIndexing(indexing, language ? language : "");
+    } catch(...) {}
+}
+
+bool zim_creator_start_zim_creation(zim_creator_t creator, const char* filepath, zim_error_t* err) {
     try {
         static_cast<zim::writer::Creator*>(creator)->startZimCreation(filepath);
         return true;
-    } catch(...) { return false; }
+    } catch(...) { capture_error(err, ZIM_ERR_IO); return false; }
 }
 
-bool zim_creator_add_item(zim_creator_t creator, zim_writer_item_t item) {
+bool zim_creator_add_item(zim_creator_t creator, zim_writer_item_t item, zim_error_t* err) {
     try {
         auto shared_item = *static_cast<std::shared_ptr<zim::writer::Item>*>(item);
         static_cast<zim::writer::Creator*>(creator)->addItem(shared_item);
         return true;
-    } catch(...) { return false; }
+    } catch(...) { capture_error(err); return false; }
 }
 
-bool zim_creator_add_metadata(zim_creator_t creator, const char* name, const char* content) {
+bool zim_creator_add_metadata(zim_creator_t creator, const char* name, const char* content, zim_error_t* err) {
     try {
         static_cast<zim::writer::Creator*>(creator)->addMetadata(name, content);
         return true;
-    } catch(...) { return false; }
+    } catch(...) { capture_error(err); return false; }
 }
 
-bool zim_creator_add_illustration(zim_creator_t creator, unsigned int size, const char* content, uint64_t content_len) {
+bool zim_creator_add_illustration(zim_creator_t creator, unsigned int size, const char* content, uint64_t content_len, zim_error_t* err) {
     try {
         std::string s_content(content, content_len);
         static_cast<zim::writer::Creator*>(creator)->addIllustration(size, s_content);
         return true;
-    } catch(...) { return false; }
+    } catch(...) { capture_error(err); return false; }
 }
 
-bool zim_creator_set_main_path(zim_creator_t creator, const char* main_path) {
+bool zim_creator_set_main_path(zim_creator_t creator, const char* main_path, zim_error_t* err) {
     try {
         static_cast<zim::writer::Creator*>(creator)->setMainPath(main_path);
         return true;
-    } catch(...) { return false; }
+    } catch(...) { capture_error(err); return false; }
 }
 
-bool zim_creator_finish_zim_creation(zim_creator_t creator) {
+bool zim_creator_finish_zim_creation(zim_creator_t creator, zim_error_t* err) {
     try {
         static_cast<zim::writer::Creator*>(creator)->finishZimCreation();
         return true;
-    } catch(...) { return false; }
+    } catch(...) { capture_error(err); return false; }
 }
 
-zim_writer_item_t zim_writer_string_item_new(const char* path, const char* mimetype, const char* title, const char* content, uint64_t content_len, bool front_article) {
+zim_writer_item_t zim_writer_string_item_new(const char* path, const char* mimetype, const char* title, const char* content, uint64_t content_len, bool front_article, zim_error_t* err) {
     try {
         zim::writer::Hints hints;
         if (front_article) hints[zim::writer::FRONT_ARTICLE] = 1;
@@ -394,17 +1129,17 @@ zim_writer_item_t zim_writer_string_item_new(const char* path, const char* mimet
         
         auto item = zim::writer::StringItem::create(path, mimetype, title, hints, s_content);
         return new std::shared_ptr<zim::writer::Item>(item);
-    } catch(...) { return nullptr; }
+    } catch(...) { capture_error(err); return nullptr; }
 }
 
-zim_writer_item_t zim_writer_file_item_new(const char* path, const char* mimetype, const char* title, const char* filepath, bool front_article) {
+zim_writer_item_t zim_writer_file_item_new(const char* path, const char* mimetype, const char* title, const char* filepath, bool front_article, zim_error_t* err) {
     try {
         zim::writer::Hints hints;
         if (front_article) hints[zim::writer::FRONT_ARTICLE] = 1;
         
         auto item = std::make_shared<zim::writer::FileItem>(path, mimetype, title, hints, filepath);
         return new std::shared_ptr<zim::writer::Item>(item);
-    } catch(...) { return nullptr; }
+    } catch(...) { capture_error(err); return nullptr; }
 }
 
 void zim_writer_item_free(zim_writer_item_t item) {
@@ -10,7 +10,9 @@ extern "C" {
 
 typedef void* zim_archive_t;
 typedef void* zim_entry_t;
+typedef void* zim_entry_cursor_t;
 typedef void* zim_item_t;
+typedef void* zim_blob_t;
 typedef void* zim_query_t;
 typedef void* zim_searcher_t;
 typedef void* zim_search_t;
@@ -23,20 +25,127 @@ typedef void* zim_suggestion_iterator_t;
 typedef void* zim_creator_t;
 typedef void* zim_writer_item_t;
 
+// Error categories reported through zim_error_t.code
+typedef enum {
+    ZIM_OK = 0,
+    ZIM_ERR_UNKNOWN,
+    ZIM_ERR_NOT_FOUND,
+    ZIM_ERR_INVALID_ARCHIVE,
+    ZIM_ERR_DUPLICATE_PATH,
+    ZIM_ERR_NO_FULLTEXT_INDEX,
+    ZIM_ERR_CREATOR_STATE,
+    ZIM_ERR_IO
+} zim_error_code_t;
+
+// Filled by fallible calls when they fail. May be NULL.
+typedef struct {
+    int code;
+    char* message; // Caller must free()
+} zim_error_t;
+
 // Archive
-zim_archive_t zim_archive_new(const char* path);
+zim_archive_t zim_archive_new(const char* path, zim_error_t* err);
+// preload_xapian_db < 0 and preload_dirent_ranges < 0 keep the libzim defaults
+zim_archive_t zim_archive_new_with_config(const char* path, int preload_xapian_db, int preload_dirent_ranges, zim_error_t* err);
+zim_archive_t zim_archive_new_from_fd(int fd, zim_error_t* err);
+zim_archive_t zim_archive_new_from_fd_range(int fd, uint64_t offset, uint64_t size, zim_error_t* err);
 void zim_archive_free(zim_archive_t archive);
 uint64_t zim_archive_get_entry_count(zim_archive_t archive);
 bool zim_archive_has_entry_by_path(zim_archive_t archive, const char* path);
-zim_entry_t zim_archive_get_entry_by_path(zim_archive_t archive, const char* path);
-zim_entry_t zim_archive_get_main_entry(zim_archive_t archive);
-zim_entry_t zim_archive_get_entry_by_index(zim_archive_t archive, uint32_t idx);
+zim_entry_t zim_archive_get_entry_by_path(zim_archive_t archive, const char* path, zim_error_t* err);
+zim_entry_t zim_archive_get_main_entry(zim_archive_t archive, zim_error_t* err);
+zim_entry_t zim_archive_get_entry_by_index(zim_archive_t archive, uint32_t idx, zim_error_t* err);
+bool zim_archive_has_entry_by_title(zim_archive_t archive, const char* title);
+zim_entry_t zim_archive_get_random_entry(zim_archive_t archive, zim_error_t* err);
+zim_entry_t zim_archive_get_entry_by_title(zim_archive_t archive, const char* title, zim_error_t* err);
+zim_entry_t zim_archive_get_entry_by_title_index(zim_archive_t archive, uint32_t idx, zim_error_t* err);
 bool zim_archive_has_fulltext_index(zim_archive_t archive);
+char* zim_archive_get_uuid(zim_archive_t archive); // Caller must free()
+uint64_t zim_archive_get_filesize(zim_archive_t archive);
+bool zim_archive_is_multipart(zim_archive_t archive);
+bool zim_archive_has_new_namespace_scheme(zim_archive_t archive);
+
+// Integrity
+bool zim_archive_has_checksum(zim_archive_t archive);
+char* zim_archive_get_checksum(zim_archive_t archive, zim_error_t* err); // Caller must free()
+bool zim_archive_verify_checksum(zim_archive_t archive, zim_error_t* err);
+// Mapped one by one to zim::IntegrityCheck, whose values vary across releases
+typedef enum {
+    ZIM_CHECK_CHECKSUM = 0,
+    ZIM_CHECK_DIRENT_PTRS,
+    ZIM_CHECK_DIRENT_ORDER,
+    ZIM_CHECK_TITLE_INDEX,
+    ZIM_CHECK_CLUSTER_PTRS,
+    ZIM_CHECK_CLUSTERS_OFFSETS,
+    ZIM_CHECK_DIRENT_MIMETYPES
+} zim_integrity_check_t;
+
+bool zim_archive_check_integrity(zim_archive_t archive, zim_integrity_check_t check, zim_error_t* err);
+bool zim_archive_check_redirect_loops(zim_archive_t archive, zim_error_t* err);
+
+// Caches
+uint64_t zim_get_cluster_cache_max_size();
+uint64_t zim_get_cluster_cache_current_size();
+bool zim_set_cluster_cache_max_size(uint64_t size, zim_error_t* err);
+uint64_t zim_archive_get_dirent_cache_max_size(zim_archive_t archive);
+uint64_t zim_archive_get_dirent_cache_current_size(zim_archive_t archive);
+bool zim_archive_set_dirent_cache_max_size(zim_archive_t archive, uint64_t size, zim_error_t* err);
+uint64_t zim_archive_get_dirent_lookup_cache_max_size(zim_archive_t archive);
+bool zim_archive_set_dirent_lookup_cache_max_size(zim_archive_t archive, uint64_t size, zim_error_t* err);
+
+// Statistics
+typedef struct {
+    uint32_t all_entry_count;
+    uint32_t entry_count;
+    uint32_t article_count;
+    uint32_t media_count;
+    uint32_t redirect_count;
+    int mimetype_count;
+    char** mimetypes;         // Mimetype names
+    uint64_t* mimetype_items; // Number of items per mimetype
+    uint64_t* mimetype_sizes; // Total content size per mimetype
+} zim_archive_stats_t;
+
+// Walks every user entry, the arrays must be released with zim_archive_stats_free()
+bool zim_archive_get_stats(zim_archive_t archive, zim_archive_stats_t* stats, zim_error_t* err);
+void zim_archive_stats_free(zim_archive_stats_t* stats);
+
+// Archive metadata
+char* zim_archive_get_metadata(zim_archive_t archive, const char* name, uint64_t* size, zim_error_t* err); // Caller must free()
+char** zim_archive_get_metadata_keys(zim_archive_t archive, int* count, zim_error_t* err); // Caller must free with zim_string_array_free()
+void zim_string_array_free(char** array, int count);
+char* zim_string_normalize(const char* str); // NFC form, caller must free(). NULL on error
+
+// Illustrations
+typedef struct {
+    uint32_t width;
+    uint32_t height;
+    float scale;
+} zim_illustration_info_t;
+
+unsigned int* zim_archive_get_illustration_sizes(zim_archive_t archive, int* count, zim_error_t* err); // Caller must free()
+zim_item_t zim_archive_get_illustration_item(zim_archive_t archive, unsigned int size, zim_error_t* err);
+zim_illustration_info_t* zim_archive_get_illustration_infos(zim_archive_t archive, int* count, zim_error_t* err); // Caller must free()
+zim_item_t zim_archive_get_illustration_item_by_info(zim_archive_t archive, zim_illustration_info_t info, zim_error_t* err);
+
+// Entry iteration
+zim_entry_cursor_t zim_archive_iter_by_path(zim_archive_t archive, zim_error_t* err);
+zim_entry_cursor_t zim_archive_iter_by_title(zim_archive_t archive, zim_error_t* err);
+zim_entry_cursor_t zim_archive_iter_efficient(zim_archive_t archive, zim_error_t* err);
+zim_entry_cursor_t zim_archive_find_by_path(zim_archive_t archive, const char* prefix, zim_error_t* err);
+zim_entry_cursor_t zim_archive_find_by_title(zim_archive_t archive, const char* prefix, zim_error_t* err);
+// Fills entries with up to max entries, returns how many were written (0 at the end)
+int zim_entry_cursor_next_batch(zim_entry_cursor_t cursor, zim_entry_t* entries, int max, zim_error_t* err);
+void zim_entry_cursor_free(zim_entry_cursor_t cursor);
 
 // Entry
 void zim_entry_free(zim_entry_t entry);
 bool zim_entry_is_redirect(zim_entry_t entry);
-zim_item_t zim_entry_get_item(zim_entry_t entry, bool follow);
+char* zim_entry_get_path(zim_entry_t entry);  // Caller must free()
+char* zim_entry_get_title(zim_entry_t entry); // Caller must free()
+uint32_t zim_entry_get_index(zim_entry_t entry);
+zim_entry_t zim_entry_get_redirect_entry(zim_entry_t entry, zim_error_t* err);
+zim_item_t zim_entry_get_item(zim_entry_t entry, bool follow, zim_error_t* err);
 
 // Item
 void zim_item_free(zim_item_t item);
@@ -44,21 +153,33 @@ char* zim_item_get_path(zim_item_t item);     // Caller must free()
 char* zim_item_get_title(zim_item_t item);    // Caller must free()
 char* zim_item_get_mimetype(zim_item_t item); // Caller must free()
 uint64_t zim_item_get_size(zim_item_t item);
-char* zim_item_get_data(zim_item_t item, uint64_t* size); // Caller must free()
+zim_blob_t zim_item_get_blob(zim_item_t item, zim_error_t* err);
+// Returns the file holding the item content if it is stored uncompressed, NULL otherwise
+char* zim_item_get_direct_access(zim_item_t item, uint64_t* offset); // Caller must free()
+// Copies up to len bytes of content starting at offset into buf, returns the number of bytes copied
+uint64_t zim_item_read_at(zim_item_t item, uint64_t offset, char* buf, uint64_t len, zim_error_t* err);
+
+// Blob
+void zim_blob_free(zim_blob_t blob);
+const char* zim_blob_data(zim_blob_t blob);
+uint64_t zim_blob_size(zim_blob_t blob);
 
 // --- Search API ---
-zim_query_t zim_query_new(const char* query_str);
+zim_query_t zim_query_new(const char* query_str, zim_error_t* err);
 void zim_query_free(zim_query_t query);
+bool zim_query_set_georange(zim_query_t query, float latitude, float longitude, float distance, zim_error_t* err);
 
-zim_searcher_t zim_searcher_new(zim_archive_t archive);
+zim_searcher_t zim_searcher_new(zim_archive_t archive, zim_error_t* err);
+zim_searcher_t zim_searcher_new_multi(zim_archive_t* archives, int count, zim_error_t* err);
+bool zim_searcher_add_archive(zim_searcher_t searcher, zim_archive_t archive, zim_error_t* err);
 void zim_searcher_free(zim_searcher_t searcher);
 
-zim_search_t zim_searcher_search(zim_searcher_t searcher, zim_query_t query);
+zim_search_t zim_searcher_search(zim_searcher_t searcher, zim_query_t query, zim_error_t* err);
 void zim_search_free(zim_search_t search);
 
 int zim_search_get_estimated_matches(zim_search_t search);
 
-zim_search_result_set_t zim_search_get_results(zim_search_t search, int start, int max_results);
+zim_search_result_set_t zim_search_get_results(zim_search_t search, int start, int max_results, zim_error_t* err);
 void zim_search_result_set_free(zim_search_result_set_t set);
 int zim_search_result_set_get_size(zim_search_result_set_t set);
 
@@ -74,17 +195,28 @@ char* zim_search_iterator_get_title(zim_search_iterator_t it);
 char* zim_search_iterator_get_snippet(zim_search_iterator_t it);
 int zim_search_iterator_get_score(zim_search_iterator_t it);
 int zim_search_iterator_get_word_count(zim_search_iterator_t it);
+int zim_search_iterator_get_file_index(zim_search_iterator_t it);
+char* zim_search_iterator_get_zim_id(zim_search_iterator_t it); // Caller must free()
+
+// Entry behind a search or suggestion result
+typedef struct {
+    uint32_t index;  // Path ordered index of the entry
+    char* mimetype;  // Of the item, redirects followed. Caller must free()
+    uint64_t size;   // Of the item content
+} zim_result_entry_t;
+
+bool zim_search_iterator_get_entry(zim_search_iterator_t it, zim_result_entry_t* out);
 
 // --- Suggestion API ---
-zim_suggestion_searcher_t zim_suggestion_searcher_new(zim_archive_t archive);
+zim_suggestion_searcher_t zim_suggestion_searcher_new(zim_archive_t archive, zim_error_t* err);
 void zim_suggestion_searcher_free(zim_suggestion_searcher_t searcher);
 void zim_suggestion_searcher_set_verbose(zim_suggestion_searcher_t searcher, bool verbose);
 
-zim_suggestion_search_t zim_suggestion_searcher_suggest(zim_suggestion_searcher_t searcher, const char* query);
+zim_suggestion_search_t zim_suggestion_searcher_suggest(zim_suggestion_searcher_t searcher, const char* query, zim_error_t* err);
 void zim_suggestion_search_free(zim_suggestion_search_t search);
 int zim_suggestion_search_get_estimated_matches(zim_suggestion_search_t search);
 
-zim_suggestion_result_set_t zim_suggestion_search_get_results(zim_suggestion_search_t search, int start, int max_results);
+zim_suggestion_result_set_t zim_suggestion_search_get_results(zim_suggestion_search_t search, int start, int max_results, zim_error_t* err);
 void zim_suggestion_result_set_free(zim_suggestion_result_set_t set);
 int zim_suggestion_result_set_get_size(zim_suggestion_result_set_t set);
 
@@ -98,26 +230,28 @@ char* zim_suggestion_iterator_get_path(zim_suggestion_iterator_t it);
 char* zim_suggestion_iterator_get_title(zim_suggestion_iterator_t it);
 char* zim_suggestion_iterator_get_snippet(zim_suggestion_iterator_t it);
 bool zim_suggestion_iterator_has_snippet(zim_suggestion_iterator_t it);
+bool zim_suggestion_iterator_get_entry(zim_suggestion_iterator_t it, zim_result_entry_t* out);
 
 // --- Writer API ---
 typedef void* zim_creator_t;
 typedef void* zim_writer_item_t;
 
-zim_creator_t zim_creator_new();
+zim_creator_t zim_creator_new(zim_error_t* err);
 void zim_creator_free(zim_creator_t creator);
 
 void zim_creator_config_verbose(zim_creator_t creator, bool verbose);
 void zim_creator_config_compression(zim_creator_t creator, int compression); // 1 = None, 5 = Zstd
+void zim_creator_config_indexing(zim_creator_t creator, bool indexing, const char* language); // ISO 639-3 language
 
-bool zim_creator_start_zim_creation(zim_creator_t creator, const char* filepath);
-bool zim_creator_add_item(zim_creator_t creator, zim_writer_item_t item);
-bool zim_creator_add_metadata(zim_creator_t creator, const char* name, const char* content);
-bool zim_creator_add_illustration(zim_creator_t creator, unsigned int size, const char* content, uint64_t content_len);
-bool zim_creator_set_main_path(zim_creator_t creator, const char* main_path);
-bool zim_creator_finish_zim_creation(zim_creator_t creator);
+bool zim_creator_start_zim_creation(zim_creator_t creator, const char* filepath, zim_error_t* err);
+bool zim_creator_add_item(zim_creator_t creator, zim_writer_item_t item, zim_error_t* err);
+bool zim_creator_add_metadata(zim_creator_t creator, const char* name, const char* content, zim_error_t* err);
+bool zim_creator_add_illustration(zim_creator_t creator, unsigned int size, const char* content, uint64_t content_len, zim_error_t* err);
+bool zim_creator_set_main_path(zim_creator_t creator, const char* main_path, zim_error_t* err);
+bool zim_creator_finish_zim_creation(zim_creator_t creator, zim_error_t* err);
 
-zim_writer_item_t zim_writer_string_item_new(const char* path, const char* mimetype, const char* title, const char* content, uint64_t content_len, bool front_article);
-zim_writer_item_t zim_writer_file_item_new(const char* path, const char* mimetype, const char* title, const char* filepath, bool front_article);
+zim_writer_item_t zim_writer_string_item_new(const char* path, const char* mimetype, const char* title, const char* content, uint64_t content_len, bool front_article, zim_error_t* err);
+zim_writer_item_t zim_writer_file_item_new(const char* path, const char* mimetype, const char* title, const char* filepath, bool front_article, zim_error_t* err);
 void zim_writer_item_free(zim_writer_item_t item);
 
 #ifdef __cplusplus